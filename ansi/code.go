@@ -18,6 +18,12 @@ var EraseDown = fmt.Sprintf("%sJ", csi)
 //EraseUp Erase the screen from the current line up to the top of the screen
 var EraseUp = fmt.Sprintf("%s1J", csi)
 
+//Inverse Swap foreground and background colors, used to highlight text
+var Inverse = fmt.Sprintf("%s7m", csi)
+
+//Reset Reset all text attributes
+var Reset = fmt.Sprintf("%s0m", csi)
+
 //CursorUp Move cursor up a specific amount of rows.
 func CursorUp(count int) string {
 	return fmt.Sprintf("%s%dA", csi, count)
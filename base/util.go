@@ -20,6 +20,7 @@ import (
 	"github.com/ucloud/ucloud-sdk-go/ucloud/log"
 	"github.com/ucloud/ucloud-sdk-go/ucloud/response"
 
+	"github.com/ucloud/ucloud-cli/ansi"
 	"github.com/ucloud/ucloud-cli/model"
 	"github.com/ucloud/ucloud-cli/ux"
 )
@@ -160,7 +161,7 @@ func PrintTableS(dataSet interface{}) {
 		}
 	}
 	if kind := dataSetVal.Kind(); kind == reflect.Slice || kind == reflect.Array {
-		displaySlice(dataSetVal, fieldNameList)
+		displaySlice(os.Stdout, dataSetVal, fieldNameList, nil)
 	} else {
 		panic(fmt.Sprintf("Internal error, PrintTableS expect array or slice, accept %T", dataSet))
 	}
@@ -193,18 +194,34 @@ func PrintTable(dataSet interface{}, fieldList []string) {
 	dataSetVal := reflect.ValueOf(dataSet)
 	switch dataSetVal.Kind() {
 	case reflect.Slice, reflect.Array:
-		displaySlice(dataSetVal, fieldList)
+		displaySlice(os.Stdout, dataSetVal, fieldList, nil)
 	default:
 		panic(fmt.Sprintf("PrintTable expect array,slice or map, accept %T", dataSet))
 	}
 }
 
-func displaySlice(listVal reflect.Value, fieldList []string) {
+//FprintTable 以表格方式打印数据集合到out, fieldList为空时打印所有字段, highlights中下标对应的行高亮显示
+func FprintTable(out io.Writer, dataSet interface{}, fieldList []string, highlights map[int]bool) {
+	dataSetVal := reflect.ValueOf(dataSet)
+	if kind := dataSetVal.Kind(); kind != reflect.Slice && kind != reflect.Array {
+		panic(fmt.Sprintf("FprintTable expect array or slice, accept %T", dataSet))
+	}
+	if fieldList == nil {
+		elemType := dataSetVal.Type().Elem()
+		for i := 0; i < elemType.NumField(); i++ {
+			fieldList = append(fieldList, elemType.Field(i).Name)
+		}
+	}
+	displaySlice(out, dataSetVal, fieldList, highlights)
+}
+
+func displaySlice(out io.Writer, listVal reflect.Value, fieldList []string, highlights map[int]bool) {
 	showFieldMap := make(map[string]int)
 	for _, field := range fieldList {
 		showFieldMap[field] = len([]rune(field))
 	}
 	rowList := make([]map[string]interface{}, 0)
+	highlightRows := make(map[int]bool)
 	for i := 0; i < listVal.Len(); i++ {
 		elemVal := listVal.Index(i)
 		elemType := elemVal.Type()
@@ -230,33 +247,44 @@ func displaySlice(listVal reflect.Value, fieldList []string) {
 				}
 			}
 		}
+		if highlights[i] {
+			for j := range rows {
+				highlightRows[len(rowList)+j] = true
+			}
+		}
 		rowList = append(rowList, rows...)
 	}
-	printTable(rowList, fieldList, showFieldMap)
+	printTable(out, rowList, fieldList, showFieldMap, highlightRows)
 }
 
-func printTable(rowList []map[string]interface{}, fieldList []string, fieldWidthMap map[string]int) {
+func printTable(out io.Writer, rowList []map[string]interface{}, fieldList []string, fieldWidthMap map[string]int, highlightRows map[int]bool) {
 	//打印表头
 	for _, field := range fieldList {
 		tmpl := "%-" + strconv.Itoa(fieldWidthMap[field]+GAP) + "s"
-		fmt.Printf(tmpl, field)
+		fmt.Fprintf(out, tmpl, field)
 	}
 	if len(fieldList) != 0 {
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
 	}
 
 	//打印数据
-	for _, row := range rowList {
+	for i, row := range rowList {
+		if highlightRows[i] {
+			fmt.Fprint(out, ansi.Inverse)
+		}
 		for _, field := range fieldList {
 			cutWidth := calcCutWidth(fmt.Sprintf("%v", row[field]))
 			tmpl := "%-" + strconv.Itoa(fieldWidthMap[field]-cutWidth+GAP) + "v"
 			if row[field] != nil {
-				fmt.Printf(tmpl, row[field])
+				fmt.Fprintf(out, tmpl, row[field])
 			} else {
-				fmt.Printf(tmpl, "")
+				fmt.Fprintf(out, tmpl, "")
 			}
 		}
-		fmt.Printf("\n")
+		if highlightRows[i] {
+			fmt.Fprint(out, ansi.Reset)
+		}
+		fmt.Fprintf(out, "\n")
 	}
 }
 
@@ -0,0 +1,371 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ucloud/ucloud-sdk-go/private/protocol/http"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/response"
+
+	"github.com/ucloud/ucloud-cli/base"
+)
+
+//rawRequest 通用请求, 参数不经过结构体, 在发送前合并到query中
+type rawRequest struct {
+	request.CommonBase
+	params map[string]string
+}
+
+//rawResponse 通用响应, 保留完整的响应内容
+type rawResponse struct {
+	response.CommonBase
+	body map[string]interface{}
+}
+
+//UnmarshalJSON 解析公共字段，其余字段原样保留
+func (r *rawResponse) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	body := make(map[string]interface{})
+	if err := decoder.Decode(&body); err != nil {
+		return err
+	}
+	r.body = body
+	if action, ok := body["Action"].(string); ok {
+		r.Action = action
+	}
+	if message, ok := body["Message"].(string); ok {
+		r.Message = message
+	}
+	if retCode, ok := body["RetCode"].(json.Number); ok {
+		code, err := retCode.Int64()
+		if err != nil {
+			return err
+		}
+		r.RetCode = int(code)
+	}
+	return nil
+}
+
+//NewCmdAPI ucloud api
+func NewCmdAPI() *cobra.Command {
+	var params []string
+	var bodyFile, paginate, output string
+	req := &rawRequest{}
+	cmd := &cobra.Command{
+		Use:   "api <Action>",
+		Short: "Invoke any UCloud API action",
+		Long: `Invoke any UCloud API action with the credential, region and project-id of current profile.
+Array params are passed with index, such as --param UHostIds.0=uhost-xxx --param UHostIds.1=uhost-yyy`,
+		Example: "ucloud api DescribeUHostInstance --param UHostIds.0=uhost-xxx --paginate Offset/Limit --output yaml",
+		Args:    cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			action := args[0]
+			req.params = make(map[string]string)
+			if bodyFile != "" {
+				content, err := ioutil.ReadFile(bodyFile)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				body := make(map[string]interface{})
+				decoder := json.NewDecoder(bytes.NewReader(content))
+				decoder.UseNumber()
+				if err := decoder.Decode(&body); err != nil {
+					base.Cxt.Printf("Error, parse %s failed: %v\n", bodyFile, err)
+					return
+				}
+				flattenParams("", body, req.params)
+			}
+			for _, param := range params {
+				kv := strings.SplitN(param, "=", 2)
+				if len(kv) != 2 || kv[0] == "" {
+					base.Cxt.Printf("Error, param %q should be in the format of Key=Value\n", param)
+					return
+				}
+				req.params[kv[0]] = kv[1]
+			}
+
+			client := newRawClient(req)
+			var result map[string]interface{}
+			var err error
+			if paginate == "" {
+				result, err = invokeRaw(client, action, req)
+			} else {
+				fields := strings.Split(paginate, "/")
+				if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+					base.Cxt.Printf("Error, paginate %q should be in the format of OffsetParam/LimitParam\n", paginate)
+					return
+				}
+				result, err = invokeRawPaginate(client, action, req, fields[0], fields[1])
+			}
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			err = printRawResponse(result, output)
+			if err != nil {
+				base.HandleError(err)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringArrayVar(&params, "param", nil, "Optional. Param of the action in the format of Key=Value. Repeat it to pass multiple params")
+	flags.StringVar(&bodyFile, "body", "", "Optional. Path of json file which contains params of the action. Params assigned by --param take precedence")
+	flags.StringVar(&paginate, "paginate", "", "Optional. Names of offset param and limit param, such as Offset/Limit. If assigned, all pages will be fetched and merged")
+	flags.StringVarP(&output, "output", "o", "json", "Optional. Accept values: json, yaml and table. Table is available if the response contains a list")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+
+	flags.SetFlagValues("paginate", "Offset/Limit")
+	flags.SetFlagValues("output", "json", "yaml", "table")
+	flags.SetFlagValuesFunc("body", func() []string {
+		return base.GetFileList(".json")
+	})
+
+	return cmd
+}
+
+//newRawClient 创建用于调用任意接口的client, 发送前把req.params合并到query中并重新签名
+func newRawClient(req *rawRequest) *sdk.Client {
+	client := sdk.NewClient(base.ClientConfig, base.AuthCredential)
+	client.AddRequestHandler(func(c *sdk.Client, req request.Common) (request.Common, error) {
+		err := req.SetProjectId(base.PickResourceID(req.GetProjectId()))
+		return req, err
+	})
+	client.AddHttpRequestHandler(func(c *sdk.Client, httpReq *http.HttpRequest) (*http.HttpRequest, error) {
+		query := httpReq.GetQueryMap()
+		delete(query, "Signature")
+		for k, v := range req.params {
+			query[k] = v
+		}
+		err := httpReq.SetQueryString(c.GetCredential().BuildCredentialedQuery(query))
+		return httpReq, err
+	})
+	client.SetupRequest(req)
+	return client
+}
+
+func invokeRaw(client *sdk.Client, action string, req *rawRequest) (map[string]interface{}, error) {
+	resp := &rawResponse{}
+	err := client.InvokeAction(action, req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
+
+//invokeRawPaginate 逐页调用, 把每一页的列表合并到第一页的响应中
+func invokeRawPaginate(client *sdk.Client, action string, req *rawRequest, offsetParam, limitParam string) (map[string]interface{}, error) {
+	offset, limit := 0, 100
+	var err error
+	if v, ok := req.params[offsetParam]; ok {
+		if offset, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("param %s should be a number, got %s", offsetParam, v)
+		}
+	}
+	if v, ok := req.params[limitParam]; ok {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return nil, fmt.Errorf("param %s should be a positive number, got %s", limitParam, v)
+		}
+	}
+
+	var result map[string]interface{}
+	listKey := ""
+	for {
+		req.params[offsetParam] = strconv.Itoa(offset)
+		req.params[limitParam] = strconv.Itoa(limit)
+		body, err := invokeRaw(client, action, req)
+		if err != nil {
+			return nil, err
+		}
+		page, _ := body[listKey].([]interface{})
+		if result == nil {
+			result = body
+			listKey = findListKey(body)
+			if listKey == "" {
+				return result, nil
+			}
+			page = body[listKey].([]interface{})
+		} else {
+			result[listKey] = append(result[listKey].([]interface{}), page...)
+		}
+		count := len(page)
+		total := -1
+		if n, ok := body["TotalCount"].(json.Number); ok {
+			if v, err := n.Int64(); err == nil {
+				total = int(v)
+			}
+		}
+		offset += limit
+		if count < limit || (total >= 0 && offset >= total) {
+			break
+		}
+	}
+	return result, nil
+}
+
+//findListKey 找到响应中的列表字段, 如 UHostSet, DataSet
+func findListKey(body map[string]interface{}) string {
+	keys := make([]string, 0, len(body))
+	for k, v := range body {
+		if _, ok := v.([]interface{}); ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+//flattenParams 把json对象展开为接口参数, 数组展开为 Key.0, Key.1, 对象展开为 Key.SubKey
+func flattenParams(prefix string, value interface{}, params map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, sub := range v {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flattenParams(key, sub, params)
+		}
+	case []interface{}:
+		for i, sub := range v {
+			flattenParams(fmt.Sprintf("%s.%d", prefix, i), sub, params)
+		}
+	case nil:
+	default:
+		params[prefix] = fmt.Sprintf("%v", v)
+	}
+}
+
+var goIdentRegexp = regexp.MustCompile(`^[A-Z][a-zA-Z0-9_]*$`)
+
+func printRawResponse(body map[string]interface{}, output string) error {
+	switch output {
+	case "json":
+		return base.PrintJSON(body, base.Cxt.GetWriter())
+	case "yaml":
+		content, err := yaml.Marshal(yamlValue(body))
+		if err != nil {
+			return err
+		}
+		base.Cxt.Print(string(content))
+		return nil
+	case "table":
+		listKey := findListKey(body)
+		if listKey == "" {
+			return fmt.Errorf("table output is unavailable, no list found in the response")
+		}
+		printRawTable(body[listKey].([]interface{}))
+		return nil
+	}
+	return fmt.Errorf("output %s is not supported, accept values: json, yaml and table", output)
+}
+
+//yamlValue json.Number转换为数值, 避免yaml输出为字符串
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, sub := range v {
+			m[k] = yamlValue(sub)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, sub := range v {
+			l[i] = yamlValue(sub)
+		}
+		return l
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return value
+}
+
+//printRawTable 把列表中对象的标量字段作为列打印表格
+func printRawTable(list []interface{}) {
+	columns := []string{}
+	seen := make(map[string]bool)
+	for _, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			switch obj[k].(type) {
+			case []interface{}, map[string]interface{}:
+				continue
+			}
+			if !seen[k] && goIdentRegexp.MatchString(k) {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	fields := make([]reflect.StructField, len(columns))
+	for i, col := range columns {
+		fields[i] = reflect.StructField{
+			Name: col,
+			Type: reflect.TypeOf(""),
+		}
+	}
+	rowType := reflect.StructOf(fields)
+	rows := reflect.MakeSlice(reflect.SliceOf(rowType), 0, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		row := reflect.New(rowType).Elem()
+		for i, col := range columns {
+			if v, ok := obj[col]; ok && v != nil {
+				row.Field(i).SetString(fmt.Sprintf("%v", v))
+			}
+		}
+		rows = reflect.Append(rows, row)
+	}
+	base.PrintTableS(rows.Interface())
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeTestJSON(t *testing.T, text string) map[string]interface{} {
+	body := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		t.Fatalf("decode %q failed: %v", text, err)
+	}
+	return body
+}
+
+type flattenParamsTest struct {
+	input          string
+	expectedParams map[string]string
+}
+
+func (test *flattenParamsTest) run(t *testing.T) {
+	params := map[string]string{}
+	flattenParams("", decodeTestJSON(t, test.input), params)
+	if !reflect.DeepEqual(params, test.expectedParams) {
+		t.Errorf("flattenParams(%q), expected %v, got %v", test.input, test.expectedParams, params)
+	}
+}
+
+func TestFlattenParams(t *testing.T) {
+	tests := []flattenParamsTest{
+		{
+			input:          `{"Region":"cn-bj2","Limit":10,"Force":true}`,
+			expectedParams: map[string]string{"Region": "cn-bj2", "Limit": "10", "Force": "true"},
+		},
+		{
+			input:          `{"UHostIds":["uhost-1","uhost-2"]}`,
+			expectedParams: map[string]string{"UHostIds.0": "uhost-1", "UHostIds.1": "uhost-2"},
+		},
+		{
+			input: `{"Disks":[{"IsBoot":"True","Size":20},{"IsBoot":"False","Size":100}],"Login":{"Mode":"Password"}}`,
+			expectedParams: map[string]string{
+				"Disks.0.IsBoot": "True",
+				"Disks.0.Size":   "20",
+				"Disks.1.IsBoot": "False",
+				"Disks.1.Size":   "100",
+				"Login.Mode":     "Password",
+			},
+		},
+		{
+			input:          `{"Tag":null,"Name":""}`,
+			expectedParams: map[string]string{"Name": ""},
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type findListKeyTest struct {
+	input       string
+	expectedKey string
+}
+
+func (test *findListKeyTest) run(t *testing.T) {
+	key := findListKey(decodeTestJSON(t, test.input))
+	if key != test.expectedKey {
+		t.Errorf("findListKey(%q), expected %q, got %q", test.input, test.expectedKey, key)
+	}
+}
+
+func TestFindListKey(t *testing.T) {
+	tests := []findListKeyTest{
+		{input: `{"RetCode":0,"TotalCount":1,"UHostSet":[{"UHostId":"uhost-1"}]}`, expectedKey: "UHostSet"},
+		{input: `{"RetCode":0,"DataSet":[],"Action":"DescribeEIPResponse"}`, expectedKey: "DataSet"},
+		{input: `{"RetCode":0,"ZSet":[],"ASet":[]}`, expectedKey: "ASet"},
+		{input: `{"RetCode":0,"Message":"ok"}`, expectedKey: ""},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	"github.com/ucloud/ucloud-sdk-go/services/unet"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//batchOp ops文件中的一行, 例如 {"command":"uhost stop","args":["--uhost-id","uhost-xxx"]}
+type batchOp struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+//batchItem 解析后的一个任务
+type batchItem struct {
+	request.Common
+	line  int
+	op    batchOp
+	async bool
+	//issued 接口调用已成功, 之后的失败(如等待超时)不再重试, 避免重复关机或改配
+	issued bool
+}
+
+//batchReportItem 单个任务的执行报告
+type batchReportItem struct {
+	Line     int      `json:"line"`
+	Command  string   `json:"command"`
+	Args     []string `json:"args"`
+	Success  bool     `json:"success"`
+	Attempts int      `json:"attempts"`
+	Logs     []string `json:"logs"`
+}
+
+//batchBuilders 支持批量执行的命令, 参数与对应的命令保持一致
+var batchBuilders = map[string]func(flags *pflag.FlagSet) (request.Common, *bool){
+	"uhost stop": func(flags *pflag.FlagSet) (request.Common, *bool) {
+		req := base.BizClient.NewStopUHostInstanceRequest()
+		req.UHostId = flags.String("uhost-id", "", "")
+		req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "")
+		req.Region = flags.String("region", base.ConfigIns.Region, "")
+		req.Zone = flags.String("zone", "", "")
+		return req, flags.Bool("async", false, "")
+	},
+	"uhost start": func(flags *pflag.FlagSet) (request.Common, *bool) {
+		req := base.BizClient.NewStartUHostInstanceRequest()
+		req.UHostId = flags.String("uhost-id", "", "")
+		req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "")
+		req.Region = flags.String("region", base.ConfigIns.Region, "")
+		req.Zone = flags.String("zone", "", "")
+		return req, flags.Bool("async", false, "")
+	},
+	"uhost delete": func(flags *pflag.FlagSet) (request.Common, *bool) {
+		req := base.BizClient.NewTerminateUHostInstanceRequest()
+		req.UHostId = flags.String("uhost-id", "", "")
+		req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "")
+		req.Region = flags.String("region", base.ConfigIns.Region, "")
+		req.Zone = flags.String("zone", "", "")
+		flags.Bool("destory", false, "")
+		req.ReleaseEIP = flags.Bool("release-eip", true, "")
+		req.ReleaseUDisk = flags.Bool("delete-cloud-disk", false, "")
+		return req, nil
+	},
+	"uhost resize": func(flags *pflag.FlagSet) (request.Common, *bool) {
+		req := base.BizClient.NewResizeUHostInstanceRequest()
+		req.UHostId = flags.String("uhost-id", "", "")
+		req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "")
+		req.Region = flags.String("region", base.ConfigIns.Region, "")
+		req.Zone = flags.String("zone", "", "")
+		req.CPU = flags.Int("cpu", 0, "")
+		req.Memory = flags.Int("memory-gb", 0, "")
+		req.DiskSpace = flags.Int("data-disk-size-gb", 0, "")
+		req.BootDiskSpace = flags.Int("system-disk-size-gb", 0, "")
+		req.NetCapValue = flags.Int("net-cap", 0, "")
+		return req, flags.Bool("async", false, "")
+	},
+	"eip release": func(flags *pflag.FlagSet) (request.Common, *bool) {
+		req := base.BizClient.NewReleaseEIPRequest()
+		req.EIPId = flags.String("eip-id", "", "")
+		req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "")
+		req.Region = flags.String("region", base.ConfigIns.Region, "")
+		return req, nil
+	},
+	"udisk delete": func(flags *pflag.FlagSet) (request.Common, *bool) {
+		req := base.BizClient.NewDeleteUDiskRequest()
+		req.UDiskId = flags.String("udisk-id", "", "")
+		req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "")
+		req.Region = flags.String("region", base.ConfigIns.Region, "")
+		req.Zone = flags.String("zone", base.ConfigIns.Zone, "")
+		return req, nil
+	},
+}
+
+//batchIDFlags 各命令必填的资源ID选项
+var batchIDFlags = map[string]string{
+	"uhost stop":   "uhost-id",
+	"uhost start":  "uhost-id",
+	"uhost delete": "uhost-id",
+	"uhost resize": "uhost-id",
+	"eip release":  "eip-id",
+	"udisk delete": "udisk-id",
+}
+
+//NewCmdBatch ucloud batch
+func NewCmdBatch() *cobra.Command {
+	var file, report string
+	var fromStdin bool
+	var parallel, retry int
+	commands := make([]string, 0, len(batchBuilders))
+	for name := range batchBuilders {
+		commands = append(commands, name)
+	}
+	sort.Strings(commands)
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Execute operations in batch from a file or stdin",
+		Long: fmt.Sprintf(`Execute operations in batch from a file or stdin, and output a report of success/failure per operation in json format.
+Each line of the file is a json object with command and args, such as {"command":"uhost stop","args":["--uhost-id","uhost-xxx"]}.
+Blank lines and lines starting with '#' are ignored. Supported commands: %s`, strings.Join(commands, ", ")),
+		Example: "ucloud batch -f ops.jsonl --parallel 5 --retry 2 --report report.json",
+		Run: func(c *cobra.Command, args []string) {
+			var in io.Reader
+			if fromStdin {
+				in = os.Stdin
+			} else if file != "" {
+				f, err := os.Open(file)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				defer f.Close()
+				in = f
+			} else {
+				base.Cxt.Println("Error, either --file or --from-stdin should be assigned")
+				return
+			}
+			items, err := parseBatchOps(in)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if len(items) == 0 {
+				base.Cxt.Println("no operation to execute")
+				return
+			}
+
+			reqs := make([]request.Common, len(items))
+			for idx, item := range items {
+				reqs[idx] = item
+			}
+			coAction := newConcurrentAction(reqs, batchAction)
+			coAction.setParallel(parallel)
+			coAction.setRetry(retry)
+			coAction.setRetryIf(func(req request.Common) bool {
+				return !req.(*batchItem).issued
+			})
+			coAction.setQuiet(true)
+			results := coAction.Do()
+
+			reportItems := make([]batchReportItem, len(items))
+			success := 0
+			for idx, item := range items {
+				reportItems[idx] = batchReportItem{
+					Line:     item.line,
+					Command:  item.op.Command,
+					Args:     item.op.Args,
+					Success:  results[idx].Success,
+					Attempts: results[idx].Attempts,
+					Logs:     results[idx].Logs,
+				}
+				if results[idx].Success {
+					success++
+				}
+			}
+			if report == "" {
+				base.PrintJSON(reportItems, base.Cxt.GetWriter())
+				return
+			}
+			content, err := json.MarshalIndent(reportItems, "", "  ")
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			err = ioutil.WriteFile(report, content, base.LocalFileMode)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			base.Cxt.Printf("total:%d, success:%d, fail:%d. report written to %s\n", len(items), success, len(items)-success, report)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(&file, "file", "f", "", "Optional. Path of the file which contains operations, one json object per line")
+	flags.BoolVar(&fromStdin, "from-stdin", false, "Optional. Read operations from stdin instead of file")
+	flags.IntVar(&parallel, "parallel", 10, "Optional. The maximum number of operations executed at the same time")
+	flags.IntVar(&retry, "retry", 0, "Optional. Times to retry a failed operation")
+	flags.StringVar(&report, "report", "", "Optional. Path of file to write the report. Print to stdout by default")
+	flags.SetFlagValuesFunc("file", func() []string {
+		return base.GetFileList(".jsonl")
+	})
+
+	return cmd
+}
+
+func parseBatchOps(in io.Reader) ([]*batchItem, error) {
+	items := []*batchItem{}
+	scanner := bufio.NewScanner(in)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		op := batchOp{}
+		err := json.Unmarshal([]byte(text), &op)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		item, err := newBatchItem(op)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		item.line = line
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func newBatchItem(op batchOp) (*batchItem, error) {
+	op.Command = strings.Join(strings.Fields(op.Command), " ")
+	builder, ok := batchBuilders[op.Command]
+	if !ok {
+		return nil, fmt.Errorf("command %q is not supported", op.Command)
+	}
+	flags := pflag.NewFlagSet(op.Command, pflag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	req, async := builder(flags)
+	if err := flags.Parse(op.Args); err != nil {
+		return nil, fmt.Errorf("%s: %v", op.Command, err)
+	}
+	if len(flags.Args()) > 0 {
+		return nil, fmt.Errorf("%s: unexpected arguments %v", op.Command, flags.Args())
+	}
+	idFlag := flags.Lookup(batchIDFlags[op.Command])
+	if idFlag.Value.String() == "" {
+		return nil, fmt.Errorf("%s: flag --%s is required", op.Command, idFlag.Name)
+	}
+	flags.Set(idFlag.Name, base.PickResourceID(idFlag.Value.String()))
+
+	switch req := req.(type) {
+	case *uhost.TerminateUHostInstanceRequest:
+		if destory, _ := flags.GetBool("destory"); destory {
+			req.Destroy = sdk.Int(1)
+		} else {
+			req.Destroy = sdk.Int(0)
+		}
+	case *uhost.ResizeUHostInstanceRequest:
+		if *req.CPU == 0 {
+			req.CPU = nil
+		}
+		if *req.Memory == 0 {
+			req.Memory = nil
+		} else {
+			*req.Memory *= 1024
+		}
+		if *req.DiskSpace == 0 {
+			req.DiskSpace = nil
+		}
+		if *req.BootDiskSpace == 0 {
+			req.BootDiskSpace = nil
+		}
+		if *req.NetCapValue == 0 {
+			req.NetCapValue = nil
+		}
+	}
+	item := &batchItem{
+		Common: req,
+		op:     op,
+	}
+	if async != nil {
+		item.async = *async
+	}
+	return item, nil
+}
+
+func batchAction(creq request.Common) (bool, []string) {
+	item := creq.(*batchItem)
+	switch req := item.Common.(type) {
+	case *uhost.StopUHostInstanceRequest:
+		ok, logs := stopUHost(req, true)
+		return item.waitUHost(ok, logs, *req.UHostId, []string{status.HOST_STOPPED})
+	case *uhost.StartUHostInstanceRequest:
+		ok, logs := startUHost(req, true)
+		return item.waitUHost(ok, logs, *req.UHostId, []string{status.HOST_RUNNING})
+	case *uhost.TerminateUHostInstanceRequest:
+		return deleteUHost(req)
+	case *uhost.ResizeUHostInstanceRequest:
+		ok, logs := resizeUHost(req, true)
+		return item.waitUHost(ok, logs, *req.UHostId, []string{status.HOST_RUNNING, status.HOST_STOPPED})
+	case *unet.ReleaseEIPRequest:
+		return releaseEIP(req)
+	case *udisk.DeleteUDiskRequest:
+		return deleteUDisk(req)
+	}
+	return false, []string{fmt.Sprintf("command %q is not supported", item.op.Command)}
+}
+
+//waitUHost 接口调用成功后等待主机状态, 等待失败时不会重试
+func (item *batchItem) waitUHost(ok bool, logs []string, uhostID string, states []string) (bool, []string) {
+	if !ok {
+		return false, logs
+	}
+	item.issued = true
+	if item.async {
+		return true, logs
+	}
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	text := fmt.Sprintf("waiting for uhost[%s]", uhostID)
+	err := waitUHostState(uhostID, item.GetProjectId(), item.GetRegion(), item.GetZone(), text, states, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, logs
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+)
+
+type parseBatchOpsTest struct {
+	input         string
+	expectedLines []int
+	expectedErr   string
+}
+
+func (test *parseBatchOpsTest) run(t *testing.T) {
+	items, err := parseBatchOps(strings.NewReader(test.input))
+	if test.expectedErr != "" {
+		if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+			t.Errorf("parseBatchOps(%q), expected error containing %q, got %v", test.input, test.expectedErr, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("parseBatchOps(%q), unexpected error: %v", test.input, err)
+	}
+	lines := []int{}
+	for _, item := range items {
+		lines = append(lines, item.line)
+	}
+	if !reflect.DeepEqual(lines, test.expectedLines) {
+		t.Errorf("parseBatchOps(%q), expected lines %v, got %v", test.input, test.expectedLines, lines)
+	}
+}
+
+func TestParseBatchOps(t *testing.T) {
+	tests := []parseBatchOpsTest{
+		{
+			input:         `{"command":"uhost stop","args":["--uhost-id","uhost-1"]}`,
+			expectedLines: []int{1},
+		},
+		{
+			input: "# stop first\n\n" +
+				`{"command":"uhost stop","args":["--uhost-id","uhost-1"]}` + "\n" +
+				`{"command":"eip release","args":["--eip-id","eip-1"]}`,
+			expectedLines: []int{3, 4},
+		},
+		{
+			input:       `{"command":"uhost stop","args":["--uhost-id","uhost-1"]}` + "\n{bad json}",
+			expectedErr: "line 2",
+		},
+		{
+			input:       `{"command":"uhost create","args":[]}`,
+			expectedErr: `command "uhost create" is not supported`,
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type newBatchItemTest struct {
+	op          batchOp
+	expectedErr string
+	check       func(item *batchItem) bool
+}
+
+func (test *newBatchItemTest) run(t *testing.T) {
+	item, err := newBatchItem(test.op)
+	if test.expectedErr != "" {
+		if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+			t.Errorf("newBatchItem(%v), expected error containing %q, got %v", test.op, test.expectedErr, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("newBatchItem(%v), unexpected error: %v", test.op, err)
+	}
+	if test.check != nil && !test.check(item) {
+		t.Errorf("newBatchItem(%v), unexpected item: %+v", test.op, item)
+	}
+}
+
+func TestNewBatchItem(t *testing.T) {
+	tests := []newBatchItemTest{
+		{
+			op: batchOp{Command: " uhost   stop ", Args: []string{"--uhost-id", "uhost-1/web", "--async"}},
+			check: func(item *batchItem) bool {
+				req, ok := item.Common.(*uhost.StopUHostInstanceRequest)
+				return ok && *req.UHostId == "uhost-1" && item.async && item.op.Command == "uhost stop"
+			},
+		},
+		{
+			op: batchOp{Command: "uhost resize", Args: []string{"--uhost-id", "uhost-1", "--memory-gb", "4"}},
+			check: func(item *batchItem) bool {
+				req := item.Common.(*uhost.ResizeUHostInstanceRequest)
+				return req.CPU == nil && req.Memory != nil && *req.Memory == 4096 && req.DiskSpace == nil && req.NetCapValue == nil
+			},
+		},
+		{
+			op: batchOp{Command: "uhost delete", Args: []string{"--uhost-id", "uhost-1", "--destory"}},
+			check: func(item *batchItem) bool {
+				req := item.Common.(*uhost.TerminateUHostInstanceRequest)
+				return *req.Destroy == 1
+			},
+		},
+		{
+			op:          batchOp{Command: "uhost stop", Args: []string{}},
+			expectedErr: "flag --uhost-id is required",
+		},
+		{
+			op:          batchOp{Command: "uhost stop", Args: []string{"--uhost-id", "uhost-1", "extra"}},
+			expectedErr: "unexpected arguments",
+		},
+		{
+			op:          batchOp{Command: "uhost stop", Args: []string{"--unknown"}},
+			expectedErr: "uhost stop:",
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/ucloud/ucloud-sdk-go/private/services/uhost"
 	"github.com/ucloud/ucloud-sdk-go/services/udisk"
 	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
 
 	"github.com/ucloud/ucloud-cli/base"
 	"github.com/ucloud/ucloud-cli/model/status"
@@ -40,15 +41,20 @@ func NewCmdDisk() *cobra.Command {
 	writer := base.Cxt.GetWriter()
 	cmd.AddCommand(NewCmdDiskCreate(writer))
 	cmd.AddCommand(NewCmdDiskList(writer))
+	cmd.AddCommand(NewCmdDiskDescribe(writer))
+	cmd.AddCommand(NewCmdDiskUpdate(writer))
+	cmd.AddCommand(NewCmdDiskSetDataArk(writer))
 	cmd.AddCommand(NewCmdDiskAttach(writer))
 	cmd.AddCommand(NewCmdDiskDetach(writer))
 	cmd.AddCommand(NewCmdDiskDelete())
 	cmd.AddCommand(NewCmdDiskClone(writer))
-	cmd.AddCommand(NewCmdDiskExpand())
+	cmd.AddCommand(NewCmdDiskMigrate(writer))
+	cmd.AddCommand(NewCmdDiskExpand(writer))
 	cmd.AddCommand(NewCmdDiskSnapshot(writer))
 	cmd.AddCommand(NewCmdDiskRestore(writer))
 	cmd.AddCommand(NewCmdSnapshotList(writer))
 	cmd.AddCommand(NewCmdSnapshotDelete(writer))
+	cmd.AddCommand(NewCmdDiskSnapshotPolicy(writer))
 	return cmd
 }
 
@@ -177,6 +183,7 @@ type DiskRow struct {
 
 //NewCmdDiskList ucloud disk list
 func NewCmdDiskList(out io.Writer) *cobra.Command {
+	var watch *watchOption
 	req := base.BizClient.NewDescribeUDiskRequest()
 	typeMap := map[string]string{
 		"DataDisk":    "Oridinary-Data-Disk",
@@ -197,32 +204,33 @@ func NewCmdDiskList(out io.Writer) *cobra.Command {
 					*req.DiskType = key
 				}
 			}
-			resp, err := base.BizClient.DescribeUDisk(req)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
-			list := []DiskRow{}
-			for _, disk := range resp.DataSet {
-				row := DiskRow{
-					ResourceID:     disk.UDiskId,
-					Name:           disk.Name,
-					Group:          disk.Tag,
-					Size:           fmt.Sprintf("%dGB", disk.Size),
-					Type:           typeMap[disk.DiskType],
-					EnableDataArk:  arkModeMap[disk.UDataArkMode],
-					MountUHost:     fmt.Sprintf("%s/%s", disk.UHostName, disk.UHostIP),
-					MountPoint:     disk.DeviceName,
-					State:          disk.Status,
-					CreationTime:   base.FormatDate(disk.CreateTime),
-					ExpirationTime: base.FormatDate(disk.ExpiredTime),
+			printRows(watch, func() (interface{}, []string, error) {
+				resp, err := base.BizClient.DescribeUDisk(req)
+				if err != nil {
+					return nil, nil, err
 				}
-				if disk.UHostIP == "" {
-					row.MountUHost = ""
+				list := []DiskRow{}
+				for _, disk := range resp.DataSet {
+					row := DiskRow{
+						ResourceID:     disk.UDiskId,
+						Name:           disk.Name,
+						Group:          disk.Tag,
+						Size:           fmt.Sprintf("%dGB", disk.Size),
+						Type:           typeMap[disk.DiskType],
+						EnableDataArk:  arkModeMap[disk.UDataArkMode],
+						MountUHost:     fmt.Sprintf("%s/%s", disk.UHostName, disk.UHostIP),
+						MountPoint:     disk.DeviceName,
+						State:          disk.Status,
+						CreationTime:   base.FormatDate(disk.CreateTime),
+						ExpirationTime: base.FormatDate(disk.ExpiredTime),
+					}
+					if disk.UHostIP == "" {
+						row.MountUHost = ""
+					}
+					list = append(list, row)
 				}
-				list = append(list, row)
-			}
-			base.PrintList(list, out)
+				return list, nil, nil
+			}, out)
 		},
 	}
 	flags := cmd.Flags()
@@ -234,10 +242,185 @@ func NewCmdDiskList(out io.Writer) *cobra.Command {
 	req.DiskType = flags.String("udisk-type", "", "Optional. Optional. Type of the udisk to search. 'Oridinary-Data-Disk','Oridinary-System-Disk' or 'SSD-Data-Disk'")
 	req.Offset = cmd.Flags().Int("offset", 0, "Optional. Offset")
 	req.Limit = cmd.Flags().Int("limit", 50, "Optional. Limit")
+	watch = bindWatch(flags)
 	flags.SetFlagValues("udisk-type", "Oridinary-Data-Disk", "Oridinary-System-Disk", "SSD-Data-Disk")
 	return cmd
 }
 
+//NewCmdDiskDescribe ucloud udisk describe
+func NewCmdDiskDescribe(out io.Writer) *cobra.Command {
+	var udiskID, project, region, zone string
+	cmd := &cobra.Command{
+		Use:     "describe",
+		Short:   "Display details of a udisk instance",
+		Long:    "Display details of a udisk instance",
+		Example: "ucloud udisk describe --udisk-id bsm-xxx",
+		Run: func(c *cobra.Command, args []string) {
+			udiskID = base.PickResourceID(udiskID)
+			disk, err := describeUDiskIns(udiskID, project, region, zone)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if disk == nil {
+				base.Cxt.Printf("Error, udisk[%s] does not exist\n", udiskID)
+				return
+			}
+			mountUHost := ""
+			if disk.UHostId != "" {
+				mountUHost = fmt.Sprintf("%s/%s/%s", disk.UHostId, disk.UHostName, disk.UHostIP)
+			}
+			attrs := []base.DescribeTableRow{
+				base.DescribeTableRow{Attribute: "ResourceID", Content: disk.UDiskId},
+				base.DescribeTableRow{Attribute: "Name", Content: disk.Name},
+				base.DescribeTableRow{Attribute: "Group", Content: disk.Tag},
+				base.DescribeTableRow{Attribute: "Zone", Content: disk.Zone},
+				base.DescribeTableRow{Attribute: "Size", Content: fmt.Sprintf("%dGB", disk.Size)},
+				base.DescribeTableRow{Attribute: "Type", Content: disk.DiskType},
+				base.DescribeTableRow{Attribute: "State", Content: disk.Status},
+				base.DescribeTableRow{Attribute: "MountUHost", Content: mountUHost},
+				base.DescribeTableRow{Attribute: "DeviceName", Content: disk.DeviceName},
+				base.DescribeTableRow{Attribute: "DataArk", Content: udiskDataArkState(disk)},
+				base.DescribeTableRow{Attribute: "Snapshot", Content: fmt.Sprintf("%d/%d", disk.SnapshotCount, disk.SnapshotLimit)},
+				base.DescribeTableRow{Attribute: "ChargeType", Content: disk.ChargeType},
+				base.DescribeTableRow{Attribute: "CreationTime", Content: base.FormatDateTime(disk.CreateTime)},
+				base.DescribeTableRow{Attribute: "ExpirationTime", Content: base.FormatDateTime(disk.ExpiredTime)},
+				base.DescribeTableRow{Attribute: "Expired", Content: disk.IsExpire},
+			}
+			base.PrintList(attrs, out)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&udiskID, "udisk-id", "", "Required. Resource ID of the udisk to describe")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", base.ConfigIns.Zone, "Optional. Override default availability zone, see 'ucloud region'")
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getDiskList([]string{status.DISK_AVAILABLE, status.DISK_INUSE, status.DISK_FAILED}, project, region, zone)
+	})
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	cmd.MarkFlagRequired("udisk-id")
+	return cmd
+}
+
+//udiskDataArkState 数据方舟状态, 不支持数据方舟的云硬盘为Unsupported
+func udiskDataArkState(disk *udisk.UDiskDataSet) string {
+	if disk.UDataArkMode == "Yes" {
+		return "Enabled"
+	}
+	if disk.ArkSwitchEnable == 0 {
+		return "Unsupported"
+	}
+	return "Disabled"
+}
+
+//NewCmdDiskUpdate ucloud udisk update
+func NewCmdDiskUpdate(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	req := base.BizClient.NewRenameUDiskRequest()
+	cmd := &cobra.Command{
+		Use:     "update",
+		Short:   "Update name of udisk instances",
+		Long:    "Update name of udisk instances",
+		Example: "ucloud udisk update --udisk-id bsm-xxx --name data",
+		Run: func(c *cobra.Command, args []string) {
+			for _, id := range udiskIDs {
+				req.UDiskId = sdk.String(base.PickResourceID(id))
+				_, err := base.BizClient.RenameUDisk(req)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				fmt.Fprintf(out, "udisk[%s] updated\n", *req.UDiskId)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Required. Resource ID of udisks to update")
+	req.UDiskName = flags.String("name", "", "Required. New name of the udisks")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZone(req, flags)
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getDiskList([]string{status.DISK_AVAILABLE, status.DISK_INUSE}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	cmd.MarkFlagRequired("udisk-id")
+	cmd.MarkFlagRequired("name")
+	bindFromStdin(cmd, "udisk-id")
+	return cmd
+}
+
+//NewCmdDiskSetDataArk ucloud udisk set-dataark
+func NewCmdDiskSetDataArk(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	var enable, disable bool
+	req := base.BizClient.NewSetUDiskUDataArkModeRequest()
+	cmd := &cobra.Command{
+		Use:     "set-dataark",
+		Short:   "Enable or disable data ark(DataArk) of udisk instances",
+		Long:    "Enable or disable data ark(DataArk) of udisk instances",
+		Example: "ucloud udisk set-dataark --udisk-id bsm-xxx1,bsm-xxx2 --enable",
+		Run: func(c *cobra.Command, args []string) {
+			if enable == disable {
+				base.Cxt.Println("Error, one and only one of enable and disable should be assigned")
+				return
+			}
+			mode := "No"
+			if enable {
+				mode = "Yes"
+			}
+			reqs := []request.Common{}
+			for _, id := range udiskIDs {
+				_req := *req
+				_req.UDiskId = sdk.String(base.PickResourceID(id))
+				_req.UDataArkMode = sdk.String(mode)
+				reqs = append(reqs, &_req)
+			}
+			coAction := newConcurrentAction(reqs, setUDiskDataArk)
+			coAction.Do()
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Required. Resource ID of udisks to set data ark")
+	flags.BoolVar(&enable, "enable", false, "Optional. Enable data ark")
+	flags.BoolVar(&disable, "disable", false, "Optional. Disable data ark")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZone(req, flags)
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getDiskList([]string{status.DISK_AVAILABLE, status.DISK_INUSE}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	cmd.MarkFlagRequired("udisk-id")
+	bindFromStdin(cmd, "udisk-id")
+	return cmd
+}
+
+//setUDiskDataArk 可并发调用, 开启或关闭云硬盘的数据方舟
+func setUDiskDataArk(creq request.Common) (bool, []string) {
+	req := creq.(*udisk.SetUDiskUDataArkModeRequest)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:SetUDiskUDataArkMode, request:%v", base.ToQueryMap(req))}
+	action := "disable"
+	if *req.UDataArkMode == "Yes" {
+		action = "enable"
+	}
+	_, err := base.BizClient.SetUDiskUDataArkMode(req)
+	if err != nil {
+		text := fmt.Sprintf("%s data ark of udisk[%s] failed: %s", action, *req.UDiskId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("data ark of udisk[%s] %sd", *req.UDiskId, action)
+	block.Append(text)
+	return true, append(logs, text)
+}
+
 //NewCmdDiskAttach ucloud disk attach
 func NewCmdDiskAttach(out io.Writer) *cobra.Command {
 	var async *bool
@@ -414,10 +597,28 @@ func NewCmdDiskDelete() *cobra.Command {
 	})
 
 	cmd.MarkFlagRequired("udisk-id")
+	bindFromStdin(cmd, "udisk-id")
 
 	return cmd
 }
 
+//deleteUDisk 可并发调用的删除云硬盘操作
+func deleteUDisk(creq request.Common) (bool, []string) {
+	req := creq.(*udisk.DeleteUDiskRequest)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:DeleteUDisk, request:%v", base.ToQueryMap(req))}
+	_, err := base.BizClient.DeleteUDisk(req)
+	if err != nil {
+		text := fmt.Sprintf("delete udisk[%s] failed: %s", *req.UDiskId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("udisk[%s] deleted", *req.UDiskId)
+	block.Append(text)
+	return true, append(logs, text)
+}
+
 //NewCmdDiskClone ucloud disk clone
 func NewCmdDiskClone(out io.Writer) *cobra.Command {
 	var async *bool
@@ -480,49 +681,6 @@ func NewCmdDiskClone(out io.Writer) *cobra.Command {
 	return cmd
 }
 
-//NewCmdDiskExpand ucloud udisk expand
-func NewCmdDiskExpand() *cobra.Command {
-	var udiskIDs *[]string
-	req := base.BizClient.NewResizeUDiskRequest()
-	cmd := &cobra.Command{
-		Use:   "expand",
-		Short: "Expand udisk size",
-		Long:  "Expand udisk size",
-		Run: func(cmd *cobra.Command, args []string) {
-			if *req.Size > 8000 || *req.Size < 1 {
-				base.Cxt.Println("size-gb should be between 1 and 8000")
-				return
-			}
-			for _, id := range *udiskIDs {
-				id = base.PickResourceID(id)
-				req.UDiskId = &id
-				_, err := base.BizClient.ResizeUDisk(req)
-				if err != nil {
-					base.HandleError(err)
-					return
-				}
-				base.Cxt.Printf("udisk:[%s] expanded to %d GB\n", *req.UDiskId, *req.Size)
-			}
-		},
-	}
-	flags := cmd.Flags()
-	flags.SortFlags = false
-	udiskIDs = flags.StringSlice("udisk-id", nil, "Required. Resource ID of the udisks to expand")
-	req.Size = flags.Int("size-gb", 0, "Required. Size of the udisk after expanded. Unit: GB. Range [1,8000]")
-	req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "Optional. Assign project-id")
-	req.Region = flags.String("region", base.ConfigIns.Region, "Optional. Assign region")
-	req.Zone = flags.String("zone", base.ConfigIns.Zone, "Optional. Assign availability zone")
-
-	flags.SetFlagValuesFunc("udisk-id", func() []string {
-		return getDiskList([]string{status.DISK_AVAILABLE}, *req.ProjectId, *req.Region, *req.Zone)
-	})
-
-	cmd.MarkFlagRequired("udisk-id")
-	cmd.MarkFlagRequired("size-gb")
-
-	return cmd
-}
-
 //NewCmdDiskSnapshot ucloud udisk snapshot
 func NewCmdDiskSnapshot(out io.Writer) *cobra.Command {
 	var async *bool
@@ -757,6 +915,60 @@ func describeUdiskByID(udiskID string) (interface{}, error) {
 	return &resp.DataSet[0], nil
 }
 
+//describeUDiskIns 返回nil表示云硬盘不存在
+func describeUDiskIns(udiskID, project, region, zone string) (*udisk.UDiskDataSet, error) {
+	req := base.BizClient.NewDescribeUDiskRequest()
+	req.ProjectId = sdk.String(project)
+	req.Region = sdk.String(region)
+	req.Zone = sdk.String(zone)
+	req.UDiskId = sdk.String(udiskID)
+	resp, err := base.BizClient.DescribeUDisk(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.DataSet) < 1 {
+		return nil, nil
+	}
+	return &resp.DataSet[0], nil
+}
+
+//waitUDiskState 等待云硬盘进入wants中的某个状态
+func waitUDiskState(udiskID, project, region, zone, text string, wants []string, block *ux.Block) error {
+	poller := base.NewSpoller(func(id string) (interface{}, error) {
+		disk, err := describeUDiskIns(id, project, region, zone)
+		if err != nil || disk == nil {
+			return nil, err
+		}
+		return disk, nil
+	}, base.Cxt.GetWriter())
+	ret := poller.Sspoll(udiskID, text, wants, block)
+	if ret.Timeout {
+		return fmt.Errorf("wait udisk[%s] timeout", udiskID)
+	}
+	return ret.Err
+}
+
+//waitUDiskSnapshotState 等待快照可用
+func waitUDiskSnapshotState(snapshotID, project, region, zone, text string, block *ux.Block) error {
+	poller := base.NewSpoller(func(id string) (interface{}, error) {
+		req := base.BizClient.NewDescribeUDiskSnapshotRequest()
+		req.ProjectId = sdk.String(project)
+		req.Region = sdk.String(region)
+		req.Zone = sdk.String(zone)
+		req.SnapshotId = sdk.String(id)
+		resp, err := base.BizClient.DescribeUDiskSnapshot(req)
+		if err != nil || len(resp.DataSet) < 1 {
+			return nil, err
+		}
+		return &resp.DataSet[0], nil
+	}, base.Cxt.GetWriter())
+	ret := poller.Sspoll(snapshotID, text, []string{status.SNAPSHOT_NORMAL}, block)
+	if ret.Timeout {
+		return fmt.Errorf("wait snapshot[%s] timeout", snapshotID)
+	}
+	return ret.Err
+}
+
 func getSnapshotList(states []string, project, region, zone string) []string {
 	req := base.BizClient.NewDescribeUDiskSnapshotRequest()
 	req.Limit = sdk.Int(50)
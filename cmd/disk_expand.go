@@ -0,0 +1,314 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//UDiskExpandRow 扩容云硬盘的价格表格行
+type UDiskExpandRow struct {
+	ResourceID string
+	Name       string
+	Size       string
+	MountUHost string
+	PriceDiff  string
+}
+
+//udiskExpandItem 扩容一块云硬盘的任务
+type udiskExpandItem struct {
+	request.CommonBase
+	disk        *udisk.UDiskDataSet
+	sizeGB      int
+	couponID    string
+	postCommand string
+	sshArgs     []string
+}
+
+//NewCmdDiskExpand ucloud udisk expand
+func NewCmdDiskExpand(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	var project, region, zone, couponID, postCommand string
+	var sizeGB int
+	var autoDetach, yes bool
+	var o *sshOption
+	cmd := &cobra.Command{
+		Use:   "expand",
+		Short: "Expand udisk size",
+		Long: `Expand udisk size. The udisk should be detached, assign --auto-detach to detach it from the uhost, and attach it back after expanded.
+If expanding fails, the udisk is attached back as well. Assign --post-command to grow the partition and file system by ssh after attached back`,
+		Example: `ucloud udisk expand --udisk-id bs-xxx --size-gb 200 --auto-detach --post-command 'growpart {device} 1 && resize2fs {device}1'`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if sizeGB > 8000 || sizeGB < 1 {
+				base.Cxt.Println("size-gb should be between 1 and 8000")
+				return
+			}
+			rows := []UDiskExpandRow{}
+			reqs := []request.Common{}
+			for _, id := range udiskIDs {
+				id = base.PickResourceID(id)
+				disk, err := describeUDiskIns(id, project, region, zone)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				if disk == nil {
+					base.Cxt.Printf("Error, udisk[%s] does not exist\n", id)
+					return
+				}
+				if sizeGB <= disk.Size {
+					base.Cxt.Printf("Error, udisk[%s] is %dGB, size-gb should be greater than it\n", id, disk.Size)
+					return
+				}
+				if disk.UHostId != "" && !autoDetach {
+					base.Cxt.Printf("Error, udisk[%s] is attached to uhost[%s], detach it first or assign --auto-detach\n", id, disk.UHostId)
+					return
+				}
+				item := &udiskExpandItem{
+					disk:        disk,
+					sizeGB:      sizeGB,
+					couponID:    couponID,
+					postCommand: postCommand,
+				}
+				item.SetProjectId(project)
+				item.SetRegion(region)
+				item.SetZone(disk.Zone)
+				if postCommand != "" && disk.UHostId != "" {
+					item.sshArgs, err = udiskExpandSSHArgs(o, disk.UHostId, project, region)
+					if err != nil {
+						base.HandleError(err)
+						return
+					}
+				}
+				row := UDiskExpandRow{
+					ResourceID: disk.UDiskId,
+					Name:       disk.Name,
+					Size:       fmt.Sprintf("%dGB -> %dGB", disk.Size, sizeGB),
+					MountUHost: disk.UHostId,
+				}
+				priceReq := base.BizClient.NewDescribeUDiskUpgradePriceRequest()
+				priceReq.ProjectId = sdk.String(project)
+				priceReq.Region = sdk.String(region)
+				priceReq.Zone = sdk.String(disk.Zone)
+				priceReq.SourceId = sdk.String(disk.UDiskId)
+				priceReq.Size = sdk.Int(sizeGB)
+				priceReq.UDataArkMode = sdk.String(disk.UDataArkMode)
+				priceReq.DiskType = sdk.String(disk.DiskType)
+				resp, err := base.BizClient.DescribeUDiskUpgradePrice(priceReq)
+				if err != nil {
+					base.LogError(fmt.Sprintf("get upgrade price of udisk[%s] failed: %s", id, base.ParseError(err)))
+					row.PriceDiff = "unknown"
+				} else {
+					row.PriceDiff = fmt.Sprintf("%.2f", resp.Price)
+				}
+				rows = append(rows, row)
+				reqs = append(reqs, item)
+			}
+			base.PrintList(rows, out)
+			//只有自动卸载会影响运行中的主机, 需要确认; 否则与原来一样直接扩容, 不影响脚本调用
+			if autoDetach && !yes {
+				sure, err := ux.Prompt("Do you want to detach and expand the udisk(s)?")
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			coAction := newConcurrentAction(reqs, expandUDisk)
+			coAction.Do()
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Required. Resource ID of the udisks to expand")
+	flags.IntVar(&sizeGB, "size-gb", 0, "Required. Size of the udisk after expanded. Unit: GB. Range [1,8000]")
+	flags.BoolVar(&autoDetach, "auto-detach", false, "Optional. Detach the udisk from the uhost before expanding and attach it back after")
+	flags.StringVar(&postCommand, "post-command", "", "Optional. Command executed on the uhost by ssh after the udisk is attached back, such as growpart and resize2fs. {device} is replaced by the device name of the udisk, such as /dev/vdb")
+	flags.StringVar(&couponID, "coupon-id", "", "Optional. Coupon ID. See 'https://accountv2.ucloud.cn'")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", base.ConfigIns.Zone, "Optional. Override default availability zone, see 'ucloud region'")
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation. It takes effect when auto-detach is assigned")
+	o = bindSSHOption(flags)
+
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getDiskList([]string{status.DISK_AVAILABLE, status.DISK_INUSE}, project, region, zone)
+	})
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	flags.SetFlagValues("auto-detach", "true", "false")
+
+	cmd.MarkFlagRequired("udisk-id")
+	cmd.MarkFlagRequired("size-gb")
+
+	return cmd
+}
+
+//udiskExpandSSHArgs 连接云硬盘所挂载主机的ssh参数
+func udiskExpandSSHArgs(o *sshOption, uhostID, project, region string) ([]string, error) {
+	host, err := describeUHostByID(uhostID, project, region, "")
+	if err != nil {
+		return nil, err
+	}
+	if host == nil {
+		return nil, fmt.Errorf("uhost[%s] does not exist", uhostID)
+	}
+	gsshs, err := getSSHGssh(o, project)
+	if err != nil {
+		return nil, err
+	}
+	t, err := o.target(host.(*uhost.UHostInstanceSet), gsshs)
+	if err != nil {
+		return nil, err
+	}
+	batchOpt := *o
+	batchOpt.options = append([]string{"BatchMode=yes"}, o.options...)
+	return batchOpt.sshArgs(t), nil
+}
+
+//expandUDisk 可并发调用, 卸载云硬盘, 扩容, 重新挂载到原来的主机并执行post command. 扩容失败时也会重新挂载
+func expandUDisk(creq request.Common) (bool, []string) {
+	item := creq.(*udiskExpandItem)
+	disk := item.disk
+	project, region, zone := item.GetProjectId(), item.GetRegion(), item.GetZone()
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{}
+	uhostID := disk.UHostId
+
+	if uhostID != "" {
+		req := base.BizClient.NewDetachUDiskRequest()
+		req.ProjectId = sdk.String(project)
+		req.Region = sdk.String(region)
+		req.Zone = sdk.String(zone)
+		req.UHostId = sdk.String(uhostID)
+		req.UDiskId = sdk.String(disk.UDiskId)
+		logs = append(logs, fmt.Sprintf("api:DetachUDisk, request:%v", base.ToQueryMap(req)))
+		_, err := base.BizClient.DetachUDisk(req)
+		if err != nil {
+			text := fmt.Sprintf("detach udisk[%s] from uhost[%s] failed: %s", disk.UDiskId, uhostID, base.ParseError(err))
+			block.Append(text)
+			return false, append(logs, text)
+		}
+		text := fmt.Sprintf("udisk[%s] is detaching from uhost[%s]", disk.UDiskId, uhostID)
+		logs = append(logs, text)
+		err = waitUDiskState(disk.UDiskId, project, region, zone, text, []string{status.DISK_AVAILABLE}, block)
+		if err != nil {
+			block.Append(err.Error())
+			return false, append(logs, err.Error())
+		}
+	}
+
+	req := base.BizClient.NewResizeUDiskRequest()
+	req.ProjectId = sdk.String(project)
+	req.Region = sdk.String(region)
+	req.Zone = sdk.String(zone)
+	req.UDiskId = sdk.String(disk.UDiskId)
+	req.Size = sdk.Int(item.sizeGB)
+	if item.couponID != "" {
+		req.CouponId = sdk.String(item.couponID)
+	}
+	logs = append(logs, fmt.Sprintf("api:ResizeUDisk, request:%v", base.ToQueryMap(req)))
+	_, err := base.BizClient.ResizeUDisk(req)
+	if err == nil {
+		text := fmt.Sprintf("udisk[%s] is expanding to %dGB", disk.UDiskId, item.sizeGB)
+		logs = append(logs, text)
+		err = waitUDiskState(disk.UDiskId, project, region, zone, text, []string{status.DISK_AVAILABLE}, block)
+	}
+	expanded := err == nil
+	if !expanded {
+		text := fmt.Sprintf("expand udisk[%s] failed: %s", disk.UDiskId, base.ParseError(err))
+		block.Append(text)
+		logs = append(logs, text)
+	}
+	if uhostID == "" {
+		if expanded {
+			logs = append(logs, fmt.Sprintf("udisk[%s] expanded to %dGB", disk.UDiskId, item.sizeGB))
+		}
+		return expanded, logs
+	}
+
+	attachReq := base.BizClient.NewAttachUDiskRequest()
+	attachReq.ProjectId = sdk.String(project)
+	attachReq.Region = sdk.String(region)
+	attachReq.Zone = sdk.String(zone)
+	attachReq.UHostId = sdk.String(uhostID)
+	attachReq.UDiskId = sdk.String(disk.UDiskId)
+	logs = append(logs, fmt.Sprintf("api:AttachUDisk, request:%v", base.ToQueryMap(attachReq)))
+	_, err = base.BizClient.AttachUDisk(attachReq)
+	if err != nil {
+		text := fmt.Sprintf("attach udisk[%s] back to uhost[%s] failed: %s", disk.UDiskId, uhostID, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("udisk[%s] is attaching back to uhost[%s]", disk.UDiskId, uhostID)
+	logs = append(logs, text)
+	err = waitUDiskState(disk.UDiskId, project, region, zone, text, []string{status.DISK_INUSE}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	if !expanded {
+		return false, logs
+	}
+	logs = append(logs, fmt.Sprintf("udisk[%s] expanded to %dGB", disk.UDiskId, item.sizeGB))
+	if item.postCommand == "" {
+		return true, logs
+	}
+
+	//重新挂载后设备名可能变化
+	device := disk.DeviceName
+	if ins, err := describeUDiskIns(disk.UDiskId, project, region, zone); err == nil && ins != nil && ins.DeviceName != "" {
+		device = ins.DeviceName
+	}
+	command := strings.Replace(item.postCommand, "{device}", device, -1)
+	args := append(item.sshArgs, "--", command)
+	logs = append(logs, fmt.Sprintf("ssh %s", strings.Join(args, " ")))
+	output := &bytes.Buffer{}
+	sshCmd := exec.Command("ssh", args...)
+	sshCmd.Stdout = output
+	sshCmd.Stderr = output
+	err = sshCmd.Run()
+	if text := strings.TrimSpace(output.String()); text != "" {
+		block.Append(text)
+		logs = append(logs, text)
+	}
+	if err != nil {
+		text := fmt.Sprintf("post command on uhost[%s] failed: %v. udisk[%s] is expanded, grow the file system manually", uhostID, err, disk.UDiskId)
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text = fmt.Sprintf("post command on uhost[%s] done", uhostID)
+	block.Append(text)
+	return true, append(logs, text)
+}
@@ -0,0 +1,260 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//udiskMigrateItem 迁移一块云硬盘的任务
+type udiskMigrateItem struct {
+	request.CommonBase
+	disk         *udisk.UDiskDataSet
+	targetZone   string
+	name         string
+	uhostID      string
+	keepSnapshot bool
+}
+
+//NewCmdDiskMigrate ucloud udisk migrate
+func NewCmdDiskMigrate(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	var project, region, zone, targetZone, name, uhostID string
+	var keepSnapshot, yes bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate udisks to another availability zone",
+		Long: `Migrate udisks to another availability zone of the same region. A snapshot of each udisk is created and cloned into a new udisk in the target zone.
+The source udisks are left untouched. Detach the source udisks or stop the uhost before migrating to get consistent data`,
+		Example: "ucloud udisk migrate --udisk-id bs-xxx --target-zone cn-bj2-04 --uhost-id uhost-xxx",
+		Run: func(c *cobra.Command, args []string) {
+			if uhostID != "" {
+				uhostID = base.PickResourceID(uhostID)
+				host, err := describeUHostByID(uhostID, project, region, "")
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				if host == nil {
+					base.Cxt.Printf("Error, uhost[%s] does not exist\n", uhostID)
+					return
+				}
+				if inst := host.(*uhost.UHostInstanceSet); inst.Zone != targetZone {
+					base.Cxt.Printf("Error, uhost[%s] is in %s, not in the target zone %s\n", uhostID, inst.Zone, targetZone)
+					return
+				}
+			}
+			reqs := []request.Common{}
+			for _, id := range udiskIDs {
+				id = base.PickResourceID(id)
+				disk, err := describeUDiskIns(id, project, region, zone)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				if disk == nil {
+					base.Cxt.Printf("Error, udisk[%s] does not exist\n", id)
+					return
+				}
+				if disk.Zone == targetZone {
+					base.Cxt.Printf("Error, udisk[%s] is in %s already\n", id, targetZone)
+					return
+				}
+				item := &udiskMigrateItem{
+					disk:         disk,
+					targetZone:   targetZone,
+					name:         name,
+					uhostID:      uhostID,
+					keepSnapshot: keepSnapshot,
+				}
+				if item.name == "" {
+					item.name = disk.Name
+				} else if len(udiskIDs) > 1 {
+					item.name = fmt.Sprintf("%s-%s", name, disk.UDiskId)
+				}
+				item.SetProjectId(project)
+				item.SetRegion(region)
+				item.SetZone(disk.Zone)
+				reqs = append(reqs, item)
+			}
+			if !yes {
+				sure, err := ux.Prompt(fmt.Sprintf("Are you sure you want to migrate %d udisk(s) to %s?", len(reqs), targetZone))
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			coAction := newConcurrentAction(reqs, migrateUDisk)
+			coAction.Do()
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Required. Resource ID of the udisks to migrate")
+	flags.StringVar(&targetZone, "target-zone", "", "Required. Availability zone to migrate the udisks to")
+	flags.StringVar(&name, "name", "", "Optional. Name of the new udisks. Name of the source udisk by default. Suffixed by the source udisk ID when more than one udisk is migrated")
+	flags.StringVar(&uhostID, "uhost-id", "", "Optional. Resource ID of the uhost in the target zone to attach the new udisks to")
+	flags.BoolVar(&keepSnapshot, "keep-snapshot", false, "Optional. Keep the snapshots created for migrating")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", base.ConfigIns.Zone, "Optional. Availability zone of the source udisks")
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation.")
+
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getDiskList([]string{status.DISK_AVAILABLE, status.DISK_INUSE}, project, region, zone)
+	})
+	flags.SetFlagValuesFunc("target-zone", func() []string {
+		return getZoneList(region)
+	})
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, project, region, targetZone)
+	})
+	flags.SetFlagValues("keep-snapshot", "true", "false")
+
+	cmd.MarkFlagRequired("udisk-id")
+	cmd.MarkFlagRequired("target-zone")
+
+	return cmd
+}
+
+//migrateUDisk 可并发调用, 创建快照, 在目标可用区克隆新的云硬盘, 按需挂载到主机并删除快照
+func migrateUDisk(creq request.Common) (bool, []string) {
+	item := creq.(*udiskMigrateItem)
+	disk := item.disk
+	project, region, zone := item.GetProjectId(), item.GetRegion(), item.GetZone()
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+
+	snapshotReq := base.BizClient.NewCreateUDiskSnapshotRequest()
+	snapshotReq.ProjectId = sdk.String(project)
+	snapshotReq.Region = sdk.String(region)
+	snapshotReq.Zone = sdk.String(zone)
+	snapshotReq.UDiskId = sdk.String(disk.UDiskId)
+	snapshotReq.Name = sdk.String(fmt.Sprintf("%s-migrate-%s", disk.Name, time.Now().Format("20060102150405")))
+	snapshotReq.Comment = sdk.String("created by ucloud udisk migrate")
+	logs := []string{fmt.Sprintf("api:CreateUDiskSnapshot, request:%v", base.ToQueryMap(snapshotReq))}
+	snapshotResp, err := base.BizClient.CreateUDiskSnapshot(snapshotReq)
+	if err != nil {
+		text := fmt.Sprintf("snapshot udisk[%s] failed: %s", disk.UDiskId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if len(snapshotResp.SnapshotId) != 1 {
+		text := fmt.Sprintf("snapshot udisk[%s] failed: expect snapshot count 1, accept %d", disk.UDiskId, len(snapshotResp.SnapshotId))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	snapshotID := snapshotResp.SnapshotId[0]
+	text := fmt.Sprintf("snapshot[%s] of udisk[%s] is creating", snapshotID, disk.UDiskId)
+	logs = append(logs, text)
+	err = waitUDiskSnapshotState(snapshotID, project, region, zone, text, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+
+	cloneReq := base.BizClient.NewCloneUDiskSnapshotRequest()
+	cloneReq.ProjectId = sdk.String(project)
+	cloneReq.Region = sdk.String(region)
+	cloneReq.Zone = sdk.String(item.targetZone)
+	cloneReq.SourceId = sdk.String(snapshotID)
+	cloneReq.Name = sdk.String(item.name)
+	cloneReq.Size = sdk.Int(disk.Size)
+	cloneReq.Comment = sdk.String(fmt.Sprintf("migrated from udisk[%s] in %s", disk.UDiskId, disk.Zone))
+	if disk.UDataArkMode == "Yes" {
+		cloneReq.UDataArkMode = sdk.String("Yes")
+	}
+	logs = append(logs, fmt.Sprintf("api:CloneUDiskSnapshot, request:%v", base.ToQueryMap(cloneReq)))
+	cloneResp, err := base.BizClient.CloneUDiskSnapshot(cloneReq)
+	if err != nil {
+		text := fmt.Sprintf("clone snapshot[%s] to %s failed: %s. snapshot[%s] is kept", snapshotID, item.targetZone, base.ParseError(err), snapshotID)
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if len(cloneResp.UDiskId) != 1 {
+		text := fmt.Sprintf("clone snapshot[%s] to %s failed: expect udisk count 1, accept %d", snapshotID, item.targetZone, len(cloneResp.UDiskId))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	newID := cloneResp.UDiskId[0]
+	text = fmt.Sprintf("udisk[%s] is cloning in %s", newID, item.targetZone)
+	logs = append(logs, text)
+	err = waitUDiskState(newID, project, region, item.targetZone, text, []string{status.DISK_AVAILABLE}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+
+	if !item.keepSnapshot {
+		deleteReq := base.BizClient.NewDeleteUDiskSnapshotRequest()
+		deleteReq.ProjectId = sdk.String(project)
+		deleteReq.Region = sdk.String(region)
+		deleteReq.Zone = sdk.String(zone)
+		deleteReq.UDiskId = sdk.String(disk.UDiskId)
+		deleteReq.SnapshotId = sdk.String(snapshotID)
+		logs = append(logs, fmt.Sprintf("api:DeleteUDiskSnapshot, request:%v", base.ToQueryMap(deleteReq)))
+		_, err = base.BizClient.DeleteUDiskSnapshot(deleteReq)
+		if err != nil {
+			text := fmt.Sprintf("delete snapshot[%s] failed: %s", snapshotID, base.ParseError(err))
+			block.Append(text)
+			logs = append(logs, text)
+		}
+	}
+
+	if item.uhostID != "" {
+		attachReq := base.BizClient.NewAttachUDiskRequest()
+		attachReq.ProjectId = sdk.String(project)
+		attachReq.Region = sdk.String(region)
+		attachReq.Zone = sdk.String(item.targetZone)
+		attachReq.UHostId = sdk.String(item.uhostID)
+		attachReq.UDiskId = sdk.String(newID)
+		logs = append(logs, fmt.Sprintf("api:AttachUDisk, request:%v", base.ToQueryMap(attachReq)))
+		_, err = base.BizClient.AttachUDisk(attachReq)
+		if err != nil {
+			text := fmt.Sprintf("attach udisk[%s] to uhost[%s] failed: %s", newID, item.uhostID, base.ParseError(err))
+			block.Append(text)
+			return false, append(logs, text)
+		}
+		text := fmt.Sprintf("udisk[%s] is attaching to uhost[%s]", newID, item.uhostID)
+		logs = append(logs, text)
+		err = waitUDiskState(newID, project, region, item.targetZone, text, []string{status.DISK_INUSE}, block)
+		if err != nil {
+			block.Append(err.Error())
+			return false, append(logs, err.Error())
+		}
+	}
+	text = fmt.Sprintf("udisk[%s] migrated to %s as udisk[%s]", disk.UDiskId, item.targetZone, newID)
+	block.Append(text)
+	return true, append(logs, text)
+}
@@ -0,0 +1,471 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+)
+
+//udiskSnapshotPolicyFile 默认的快照策略文件
+var udiskSnapshotPolicyFile = base.GetConfigDir() + "/udisk_snapshot_policies.json"
+
+//udiskSnapshotDateLayout 快照名称中{date}的格式
+const udiskSnapshotDateLayout = "2006-01-02"
+
+//udiskSnapshotPolicy 云硬盘快照策略, 每个云硬盘最多一个
+type udiskSnapshotPolicy struct {
+	UDiskID      string `json:"udisk_id"`
+	ProjectID    string `json:"project_id"`
+	Region       string `json:"region"`
+	Zone         string `json:"zone"`
+	Keep         int    `json:"keep"`
+	NameTemplate string `json:"name_template"`
+}
+
+//snapshotName 按模板生成快照名称, {disk}为云硬盘名称, {id}为云硬盘资源ID, {date}为日期
+func (p *udiskSnapshotPolicy) snapshotName(diskName string, date time.Time) string {
+	r := strings.NewReplacer("{disk}", diskName, "{id}", p.UDiskID, "{date}", date.Format(udiskSnapshotDateLayout))
+	return r.Replace(p.NameTemplate)
+}
+
+//udiskSnapshotPolicyComment 快照策略创建的快照的备注, 用于识别由策略管理的快照
+const udiskSnapshotPolicyComment = "created by ucloud udisk snapshot-policy"
+
+//isManaged 是否为该策略创建的快照, 按备注和云硬盘ID判断, 云硬盘改名后仍然有效. 其他快照不会被删除
+func (p *udiskSnapshotPolicy) isManaged(snapshot udisk.UDiskSnapshotSet) bool {
+	return snapshot.UDiskId == p.UDiskID && snapshot.Comment == udiskSnapshotPolicyComment
+}
+
+//UDiskSnapshotPolicyRow 快照策略表格行
+type UDiskSnapshotPolicyRow struct {
+	UDiskID      string
+	Region       string
+	Zone         string
+	Keep         int
+	NameTemplate string
+}
+
+//UDiskSnapshotPolicyRunRow 执行快照策略的报告表格行
+type UDiskSnapshotPolicyRunRow struct {
+	UDiskID    string
+	Action     string
+	SnapshotID string
+	Snapshot   string
+	Result     string
+}
+
+//NewCmdDiskSnapshotPolicy ucloud udisk snapshot-policy
+func NewCmdDiskSnapshotPolicy(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot-policy",
+		Short: "Apply, list, delete and run snapshot policies of udisks",
+		Long: `Apply, list, delete and run snapshot policies of udisks. A policy keeps the latest snapshots of a udisk, one snapshot a day.
+Policies are stored in ` + udiskSnapshotPolicyFile + ` by default. Execute 'ucloud udisk snapshot-policy run' by cron or systemd timer daily`,
+	}
+	cmd.AddCommand(NewCmdDiskSnapshotPolicyApply(out))
+	cmd.AddCommand(NewCmdDiskSnapshotPolicyList(out))
+	cmd.AddCommand(NewCmdDiskSnapshotPolicyDelete(out))
+	cmd.AddCommand(NewCmdDiskSnapshotPolicyRun(out))
+	return cmd
+}
+
+//NewCmdDiskSnapshotPolicyApply ucloud udisk snapshot-policy apply
+func NewCmdDiskSnapshotPolicyApply(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	var project, region, zone, file string
+	policy := &udiskSnapshotPolicy{}
+	cmd := &cobra.Command{
+		Use:     "apply",
+		Short:   "Create or update snapshot policies of udisks",
+		Long:    "Create or update snapshot policies of udisks. The policy of a udisk is replaced if it exists",
+		Example: "ucloud udisk snapshot-policy apply --udisk-id bsm-xxx --keep 7 --name-template '{disk}-{date}'",
+		Run: func(c *cobra.Command, args []string) {
+			if policy.Keep < 1 {
+				base.Cxt.Printf("Error, keep should be greater than 0, got %d\n", policy.Keep)
+				return
+			}
+			if strings.Count(policy.NameTemplate, "{date}") != 1 {
+				base.Cxt.Println("Error, name-template should contain {date} once")
+				return
+			}
+			policies, err := loadUDiskSnapshotPolicies(file)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			for _, id := range udiskIDs {
+				id = base.PickResourceID(id)
+				disk, err := describeUDiskIns(id, project, region, zone)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				if disk == nil {
+					base.Cxt.Printf("Error, udisk[%s] does not exist\n", id)
+					return
+				}
+				p := *policy
+				p.UDiskID = id
+				p.ProjectID = project
+				p.Region = region
+				p.Zone = disk.Zone
+				policies[id] = &p
+			}
+			err = writeUDiskSnapshotPolicies(file, policies)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			for _, id := range udiskIDs {
+				fmt.Fprintf(out, "snapshot policy of udisk[%s] saved to %s\n", base.PickResourceID(id), file)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Required. Resource ID of udisks to apply the policy")
+	flags.IntVar(&policy.Keep, "keep", 7, "Optional. The number of snapshots to keep. Older snapshots created by the policy are deleted")
+	flags.StringVar(&policy.NameTemplate, "name-template", "{disk}-{date}", "Optional. Name of snapshots. {disk} is replaced by udisk name, {id} by udisk resource ID and {date} by date such as 2019-01-02")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", base.ConfigIns.Zone, "Optional. Override default availability zone, see 'ucloud region'")
+	bindUDiskSnapshotPolicyFile(&file, flags)
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getDiskList([]string{status.DISK_AVAILABLE, status.DISK_INUSE}, project, region, zone)
+	})
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	cmd.MarkFlagRequired("udisk-id")
+	return cmd
+}
+
+//NewCmdDiskSnapshotPolicyList ucloud udisk snapshot-policy list
+func NewCmdDiskSnapshotPolicyList(out io.Writer) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List snapshot policies",
+		Long:  "List snapshot policies",
+		Run: func(c *cobra.Command, args []string) {
+			policies, err := loadUDiskSnapshotPolicies(file)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			rows := []UDiskSnapshotPolicyRow{}
+			for _, p := range sortedUDiskSnapshotPolicies(policies) {
+				rows = append(rows, UDiskSnapshotPolicyRow{
+					UDiskID:      p.UDiskID,
+					Region:       p.Region,
+					Zone:         p.Zone,
+					Keep:         p.Keep,
+					NameTemplate: p.NameTemplate,
+				})
+			}
+			base.PrintList(rows, out)
+		},
+	}
+	bindUDiskSnapshotPolicyFile(&file, cmd.Flags())
+	return cmd
+}
+
+//NewCmdDiskSnapshotPolicyDelete ucloud udisk snapshot-policy delete
+func NewCmdDiskSnapshotPolicyDelete(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	var file string
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete snapshot policies of udisks",
+		Long:  "Delete snapshot policies of udisks. Snapshots created by the policies are not deleted",
+		Run: func(c *cobra.Command, args []string) {
+			policies, err := loadUDiskSnapshotPolicies(file)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			for _, id := range udiskIDs {
+				id = base.PickResourceID(id)
+				if _, ok := policies[id]; !ok {
+					base.Cxt.Printf("Error, snapshot policy of udisk[%s] not exist in %s\n", id, file)
+					return
+				}
+				delete(policies, id)
+			}
+			err = writeUDiskSnapshotPolicies(file, policies)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			for _, id := range udiskIDs {
+				fmt.Fprintf(out, "snapshot policy of udisk[%s] deleted\n", base.PickResourceID(id))
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Required. Resource ID of udisks whose policy to delete")
+	bindUDiskSnapshotPolicyFile(&file, flags)
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getUDiskSnapshotPolicyIDs(file)
+	})
+	cmd.MarkFlagRequired("udisk-id")
+	return cmd
+}
+
+//NewCmdDiskSnapshotPolicyRun ucloud udisk snapshot-policy run
+func NewCmdDiskSnapshotPolicyRun(out io.Writer) *cobra.Command {
+	var udiskIDs []string
+	var file string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Execute snapshot policies",
+		Long: `Execute snapshot policies. Today's snapshot is created if it does not exist, and snapshots created by the policy beyond keep are deleted.
+It is safe to run more than once a day`,
+		Example: "ucloud udisk snapshot-policy run",
+		Run: func(c *cobra.Command, args []string) {
+			policies, err := loadUDiskSnapshotPolicies(file)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			selected := sortedUDiskSnapshotPolicies(policies)
+			if len(udiskIDs) > 0 {
+				selected = []*udiskSnapshotPolicy{}
+				for _, id := range udiskIDs {
+					id = base.PickResourceID(id)
+					p, ok := policies[id]
+					if !ok {
+						base.Cxt.Printf("Error, snapshot policy of udisk[%s] not exist in %s\n", id, file)
+						return
+					}
+					selected = append(selected, p)
+				}
+			}
+			rows := []UDiskSnapshotPolicyRunRow{}
+			now := time.Now()
+			for _, p := range selected {
+				rows = append(rows, runUDiskSnapshotPolicy(p, now, dryRun)...)
+			}
+			base.PrintList(rows, out)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&udiskIDs, "udisk-id", nil, "Optional. Resource ID of udisks whose policy to execute. Execute all policies by default")
+	flags.BoolVar(&dryRun, "dry-run", false, "Optional. Only report the actions to take")
+	bindUDiskSnapshotPolicyFile(&file, flags)
+	flags.SetFlagValuesFunc("udisk-id", func() []string {
+		return getUDiskSnapshotPolicyIDs(file)
+	})
+	flags.SetFlagValues("dry-run", "true", "false")
+	return cmd
+}
+
+//runUDiskSnapshotPolicy 执行一个快照策略, 返回采取的操作
+func runUDiskSnapshotPolicy(p *udiskSnapshotPolicy, now time.Time, dryRun bool) []UDiskSnapshotPolicyRunRow {
+	rows := []UDiskSnapshotPolicyRunRow{}
+	report := func(action, snapshotID, name, result string) {
+		rows = append(rows, UDiskSnapshotPolicyRunRow{
+			UDiskID:    p.UDiskID,
+			Action:     action,
+			SnapshotID: snapshotID,
+			Snapshot:   name,
+			Result:     result,
+		})
+	}
+	disk, err := describeUDiskIns(p.UDiskID, p.ProjectID, p.Region, p.Zone)
+	if err != nil {
+		report("check", "", "", base.ParseError(err))
+		return rows
+	}
+	if disk == nil {
+		report("check", "", "", "udisk not found")
+		return rows
+	}
+	snapshots, err := getUDiskSnapshots(p.UDiskID, p.ProjectID, p.Region, p.Zone)
+	if err != nil {
+		report("check", "", "", base.ParseError(err))
+		return rows
+	}
+	managed := []udisk.UDiskSnapshotSet{}
+	for _, s := range snapshots {
+		if p.isManaged(s) {
+			managed = append(managed, s)
+		}
+	}
+	sort.Slice(managed, func(i, j int) bool {
+		return managed[i].CreateTime > managed[j].CreateTime
+	})
+
+	name := p.snapshotName(disk.Name, now)
+	exists := false
+	for _, s := range managed {
+		//云硬盘改名后名称会变化, 同时按创建日期判断今天的快照是否已存在
+		if s.Name == name || time.Unix(int64(s.CreateTime), 0).In(now.Location()).Format(udiskSnapshotDateLayout) == now.Format(udiskSnapshotDateLayout) {
+			exists = true
+		}
+	}
+	keep := p.Keep
+	if exists {
+		report("skip", "", name, "exists already")
+	} else {
+		keep--
+		if dryRun {
+			report("create", "", name, "dry run")
+		} else {
+			req := base.BizClient.NewCreateUDiskSnapshotRequest()
+			req.ProjectId = sdk.String(p.ProjectID)
+			req.Region = sdk.String(p.Region)
+			req.Zone = sdk.String(p.Zone)
+			req.UDiskId = sdk.String(p.UDiskID)
+			req.Name = sdk.String(name)
+			req.Comment = sdk.String(udiskSnapshotPolicyComment)
+			resp, err := base.BizClient.CreateUDiskSnapshot(req)
+			if err != nil {
+				report("create", "", name, base.ParseError(err))
+				//创建失败时不删除旧快照
+				return rows
+			}
+			report("create", strings.Join(resp.SnapshotId, ","), name, "success")
+		}
+	}
+
+	for idx, s := range managed {
+		if idx < keep {
+			continue
+		}
+		if dryRun {
+			report("delete", s.SnapshotId, s.Name, "dry run")
+			continue
+		}
+		req := base.BizClient.NewDeleteUDiskSnapshotRequest()
+		req.ProjectId = sdk.String(p.ProjectID)
+		req.Region = sdk.String(p.Region)
+		req.Zone = sdk.String(p.Zone)
+		req.UDiskId = sdk.String(p.UDiskID)
+		req.SnapshotId = sdk.String(s.SnapshotId)
+		_, err := base.BizClient.DeleteUDiskSnapshot(req)
+		if err != nil {
+			report("delete", s.SnapshotId, s.Name, base.ParseError(err))
+			continue
+		}
+		report("delete", s.SnapshotId, s.Name, "success")
+	}
+	return rows
+}
+
+//getUDiskSnapshots 查询所有快照, udiskID为空时查询可用区内所有云硬盘的快照
+func getUDiskSnapshots(udiskID, project, region, zone string) ([]udisk.UDiskSnapshotSet, error) {
+	req := base.BizClient.NewDescribeUDiskSnapshotRequest()
+	req.ProjectId = sdk.String(project)
+	req.Region = sdk.String(region)
+	req.Zone = sdk.String(zone)
+	req.UDiskId = sdk.String(udiskID)
+	list := []udisk.UDiskSnapshotSet{}
+	for offset, limit := 0, 100; ; offset += limit {
+		req.Offset = sdk.Int(offset)
+		req.Limit = sdk.Int(limit)
+		resp, err := base.BizClient.DescribeUDiskSnapshot(req)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, resp.DataSet...)
+		if len(resp.DataSet) < limit || len(list) >= resp.TotalCount {
+			break
+		}
+	}
+	return list, nil
+}
+
+func bindUDiskSnapshotPolicyFile(file *string, flags *pflag.FlagSet) {
+	flags.StringVar(file, "policy-file", udiskSnapshotPolicyFile, "Optional. Path of the file which stores snapshot policies")
+	flags.SetFlagValuesFunc("policy-file", func() []string {
+		return base.GetFileList(".json")
+	})
+}
+
+//loadUDiskSnapshotPolicies 读取快照策略, key为云硬盘资源ID
+func loadUDiskSnapshotPolicies(file string) (map[string]*udiskSnapshotPolicy, error) {
+	policies := make(map[string]*udiskSnapshotPolicy)
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return policies, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return policies, nil
+	}
+	list := []*udiskSnapshotPolicy{}
+	err = json.Unmarshal(content, &list)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", file, err)
+	}
+	for _, p := range list {
+		policies[p.UDiskID] = p
+	}
+	return policies, nil
+}
+
+func writeUDiskSnapshotPolicies(file string, policies map[string]*udiskSnapshotPolicy) error {
+	content, err := json.MarshalIndent(sortedUDiskSnapshotPolicies(policies), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, base.LocalFileMode)
+}
+
+func sortedUDiskSnapshotPolicies(policies map[string]*udiskSnapshotPolicy) []*udiskSnapshotPolicy {
+	list := []*udiskSnapshotPolicy{}
+	for _, p := range policies {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].UDiskID < list[j].UDiskID
+	})
+	return list
+}
+
+func getUDiskSnapshotPolicyIDs(file string) []string {
+	policies, err := loadUDiskSnapshotPolicies(file)
+	if err != nil {
+		return nil
+	}
+	list := []string{}
+	for _, p := range sortedUDiskSnapshotPolicies(policies) {
+		list = append(list, p.UDiskID+"/keep-"+strconv.Itoa(p.Keep))
+	}
+	return list
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+)
+
+type snapshotNameTest struct {
+	template     string
+	diskName     string
+	expectedName string
+}
+
+func (test *snapshotNameTest) run(t *testing.T) {
+	p := &udiskSnapshotPolicy{UDiskID: "bs-abc12", NameTemplate: test.template}
+	date := time.Date(2019, 1, 2, 15, 4, 5, 0, time.Local)
+	name := p.snapshotName(test.diskName, date)
+	if name != test.expectedName {
+		t.Errorf("snapshotName(%q, %q), expected %q, got %q", test.template, test.diskName, test.expectedName, name)
+	}
+}
+
+func TestSnapshotName(t *testing.T) {
+	tests := []snapshotNameTest{
+		{template: "{disk}-{date}", diskName: "data", expectedName: "data-2019-01-02"},
+		{template: "{id}-daily-{date}", diskName: "data", expectedName: "bs-abc12-daily-2019-01-02"},
+		{template: "backup", diskName: "data", expectedName: "backup"},
+		{template: "{disk}-{disk}", diskName: "db", expectedName: "db-db"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type isManagedTest struct {
+	snapshot udisk.UDiskSnapshotSet
+	expected bool
+}
+
+func (test *isManagedTest) run(t *testing.T) {
+	p := &udiskSnapshotPolicy{UDiskID: "bs-abc12", NameTemplate: "{disk}-{date}"}
+	if got := p.isManaged(test.snapshot); got != test.expected {
+		t.Errorf("isManaged(%+v), expected %t, got %t", test.snapshot, test.expected, got)
+	}
+}
+
+func TestIsManaged(t *testing.T) {
+	tests := []isManagedTest{
+		{
+			snapshot: udisk.UDiskSnapshotSet{UDiskId: "bs-abc12", Name: "data-2019-01-02", Comment: udiskSnapshotPolicyComment},
+			expected: true,
+		},
+		{
+			snapshot: udisk.UDiskSnapshotSet{UDiskId: "bs-abc12", Name: "renamed-2019-01-02", Comment: udiskSnapshotPolicyComment},
+			expected: true,
+		},
+		{
+			snapshot: udisk.UDiskSnapshotSet{UDiskId: "bs-abc12", Name: "data-2019-01-02", Comment: "manual"},
+			expected: false,
+		},
+		{
+			snapshot: udisk.UDiskSnapshotSet{UDiskId: "bs-def34", Name: "data-2019-01-02", Comment: udiskSnapshotPolicyComment},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+)
+
+type udiskDataArkStateTest struct {
+	disk          udisk.UDiskDataSet
+	expectedState string
+}
+
+func (test *udiskDataArkStateTest) run(t *testing.T) {
+	state := udiskDataArkState(&test.disk)
+	if state != test.expectedState {
+		t.Errorf("udiskDataArkState(%+v), expected %q, got %q", test.disk, test.expectedState, state)
+	}
+}
+
+func TestUDiskDataArkState(t *testing.T) {
+	tests := []udiskDataArkStateTest{
+		{disk: udisk.UDiskDataSet{UDataArkMode: "Yes", ArkSwitchEnable: 1}, expectedState: "Enabled"},
+		{disk: udisk.UDiskDataSet{UDataArkMode: "Yes", ArkSwitchEnable: 0}, expectedState: "Enabled"},
+		{disk: udisk.UDiskDataSet{UDataArkMode: "No", ArkSwitchEnable: 1}, expectedState: "Disabled"},
+		{disk: udisk.UDiskDataSet{UDataArkMode: "No", ArkSwitchEnable: 0}, expectedState: "Unsupported"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -26,9 +26,11 @@ import (
 
 	"github.com/ucloud/ucloud-sdk-go/services/unet"
 	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
 
 	"github.com/ucloud/ucloud-cli/base"
 	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
 )
 
 //NewCmdEIP ucloud eip
@@ -70,48 +72,49 @@ func NewCmdEIPList(out io.Writer) *cobra.Command {
 	req := base.BizClient.NewDescribeEIPRequest()
 	fetchAll := false
 	pageOff := false
+	var watch *watchOption
 	cmd := &cobra.Command{
 		Use:     "list",
 		Short:   "List all EIP instances",
 		Long:    `List all EIP instances`,
 		Example: "ucloud eip list",
 		Run: func(cmd *cobra.Command, args []string) {
-			var eipList []unet.UnetEIPSet
-			if fetchAll || pageOff {
-				list, err := fetchAllEip(*req.ProjectId, *req.Region)
-				if err != nil {
-					base.HandleError(err)
-					return
-				}
-				eipList = list
-			} else {
-				resp, err := base.BizClient.DescribeEIP(req)
-				if err != nil {
-					base.HandleError(err)
-					return
+			printRows(watch, func() (interface{}, []string, error) {
+				var eipList []unet.UnetEIPSet
+				if fetchAll || pageOff {
+					list, err := fetchAllEip(*req.ProjectId, *req.Region)
+					if err != nil {
+						return nil, nil, err
+					}
+					eipList = list
+				} else {
+					resp, err := base.BizClient.DescribeEIP(req)
+					if err != nil {
+						return nil, nil, err
+					}
+					eipList = resp.EIPSet
 				}
-				eipList = resp.EIPSet
-			}
 
-			list := make([]EIPRow, 0)
-			for _, eip := range eipList {
-				row := EIPRow{}
-				row.Name = eip.Name
-				for _, ip := range eip.EIPAddr {
-					row.IP += ip.IP + " " + ip.OperatorName + "   "
-				}
-				row.ResourceID = eip.EIPId
-				row.Group = eip.Tag
-				row.ChargeMode = eip.PayMode
-				row.Bandwidth = strconv.Itoa(eip.Bandwidth) + "Mb"
-				if eip.Resource.ResourceId != "" {
-					row.BindResource = fmt.Sprintf("%s|%s(%s)", eip.Resource.ResourceName, eip.Resource.ResourceId, eip.Resource.ResourceType)
+				list := make([]EIPRow, 0)
+				for _, eip := range eipList {
+					row := EIPRow{}
+					row.Name = eip.Name
+					for _, ip := range eip.EIPAddr {
+						row.IP += ip.IP + " " + ip.OperatorName + "   "
+					}
+					row.ResourceID = eip.EIPId
+					row.Group = eip.Tag
+					row.ChargeMode = eip.PayMode
+					row.Bandwidth = strconv.Itoa(eip.Bandwidth) + "Mb"
+					if eip.Resource.ResourceId != "" {
+						row.BindResource = fmt.Sprintf("%s|%s(%s)", eip.Resource.ResourceName, eip.Resource.ResourceId, eip.Resource.ResourceType)
+					}
+					row.Status = eip.Status
+					row.ExpirationTime = time.Unix(int64(eip.ExpireTime), 0).Format("2006-01-02")
+					list = append(list, row)
 				}
-				row.Status = eip.Status
-				row.ExpirationTime = time.Unix(int64(eip.ExpireTime), 0).Format("2006-01-02")
-				list = append(list, row)
-			}
-			base.PrintList(list, out)
+				return list, nil, nil
+			}, out)
 		},
 	}
 
@@ -122,6 +125,7 @@ func NewCmdEIPList(out io.Writer) *cobra.Command {
 	req.Limit = flags.Int("limit", 50, "Optional. Limit default 50, max value 100")
 	flags.BoolVar(&fetchAll, "list-all", false, "List all eip")
 	flags.BoolVar(&pageOff, "page-off", false, "Optional. Paging or not. Accept values: true or false")
+	watch = bindWatch(flags)
 	flags.SetFlagValues("list-all", "true", "false")
 	flags.MarkDeprecated("list-all", "please use '--page-off' instead")
 
@@ -455,6 +459,7 @@ func NewCmdEIPRelease() *cobra.Command {
 	bindProjectID(req, flags)
 	bindRegion(req, flags)
 	cmd.MarkFlagRequired("eip-id")
+	bindFromStdin(cmd, "eip-id")
 	flags.SetFlagValuesFunc("eip-id", func() []string {
 		return getAllEip(*req.ProjectId, *req.Region, []string{status.EIP_FREE}, nil)
 	})
@@ -462,6 +467,23 @@ func NewCmdEIPRelease() *cobra.Command {
 	return cmd
 }
 
+//releaseEIP 可并发调用的释放EIP操作
+func releaseEIP(creq request.Common) (bool, []string) {
+	req := creq.(*unet.ReleaseEIPRequest)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:ReleaseEIP, request:%v", base.ToQueryMap(req))}
+	_, err := base.BizClient.ReleaseEIP(req)
+	if err != nil {
+		text := fmt.Sprintf("release eip[%s] failed: %s", *req.EIPId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("eip[%s] released", *req.EIPId)
+	block.Append(text)
+	return true, append(logs, text)
+}
+
 //NewCmdEIPModifyBandwidth ucloud eip modify-bw
 func NewCmdEIPModifyBandwidth() *cobra.Command {
 	ids := []string{}
@@ -0,0 +1,828 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+
+	"github.com/ucloud/ucloud-cli/base"
+)
+
+var exportTypeList = []string{"uhost", "vpc", "firewall", "ulb", "udb"}
+
+//NewCmdExport ucloud export
+func NewCmdExport() *cobra.Command {
+	var project, region, zone, format, output string
+	var types []string
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Export existing resources as declarative specs or Terraform HCL",
+		Long:    "Export existing resources as declarative specs(yaml) or Terraform HCL, including import statements, so that resources can be managed as code",
+		Example: "ucloud export --type uhost,vpc,firewall,ulb --format hcl --output main.tf",
+		Run: func(c *cobra.Command, args []string) {
+			project = base.PickResourceID(project)
+			accepted := make(map[string]bool)
+			for _, t := range exportTypeList {
+				accepted[t] = true
+			}
+			for _, t := range types {
+				if !accepted[t] {
+					base.Cxt.Printf("Error, resource type %s is not supported, accept values: %s\n", t, strings.Join(exportTypeList, ","))
+					return
+				}
+			}
+			if format != "yaml" && format != "hcl" {
+				base.Cxt.Printf("Error, format %s is not supported, accept values: yaml, hcl\n", format)
+				return
+			}
+			spec, err := buildExportSpec(types, project, region, zone)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			var content []byte
+			if format == "hcl" {
+				content = renderExportHCL(spec)
+			} else {
+				content, err = yaml.Marshal(spec)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+			}
+			if output == "" {
+				base.Cxt.Print(string(content))
+				return
+			}
+			err = ioutil.WriteFile(output, content, 0644)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			base.Cxt.Printf("resources exported to %s\n", output)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&types, "type", exportTypeList, "Optional. Resource types to export, multiple values separated by comma. Accept values: uhost, vpc, firewall, ulb and udb")
+	flags.StringVar(&format, "format", "yaml", "Optional. Format of exported content. Accept values: yaml, hcl")
+	flags.StringVar(&output, "output", "", "Optional. Path of file to write. Print to stdout by default")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	bindZoneEmptyS(&zone, &region, flags)
+
+	flags.SetFlagValues("type", exportTypeList...)
+	flags.SetFlagValues("format", "yaml", "hcl")
+	flags.SetFlagValuesFunc("output", func() []string {
+		return base.GetFileList("")
+	})
+
+	return cmd
+}
+
+//ExportSpec 导出的资源描述
+type ExportSpec struct {
+	ProjectID      string                `yaml:"project_id"`
+	Region         string                `yaml:"region"`
+	UHosts         []UHostSpec           `yaml:"uhosts,omitempty"`
+	VPCs           []VPCSpec             `yaml:"vpcs,omitempty"`
+	Firewalls      []FirewallSpec        `yaml:"firewalls,omitempty"`
+	ULBs           []ULBSpec             `yaml:"ulbs,omitempty"`
+	UDBParamGroups []UDBParamGroupSpec   `yaml:"udb_param_groups,omitempty"`
+	Imports        []ExportImportCommand `yaml:"imports,omitempty"`
+}
+
+//ExportImportCommand 把资源导入Terraform状态的命令
+type ExportImportCommand struct {
+	Address    string `yaml:"address"`
+	ResourceID string `yaml:"resource_id"`
+}
+
+//UHostSpec 云主机描述
+type UHostSpec struct {
+	ResourceID    string          `yaml:"resource_id"`
+	Name          string          `yaml:"name"`
+	Group         string          `yaml:"group"`
+	Zone          string          `yaml:"zone"`
+	ImageID       string          `yaml:"image_id"`
+	CPU           int             `yaml:"cpu"`
+	MemoryGB      int             `yaml:"memory_gb"`
+	MachineType   string          `yaml:"machine_type,omitempty"`
+	ChargeType    string          `yaml:"charge_type"`
+	NetCapability string          `yaml:"net_capability,omitempty"`
+	VPCID         string          `yaml:"vpc_id,omitempty"`
+	SubnetID      string          `yaml:"subnet_id,omitempty"`
+	PrivateIP     string          `yaml:"private_ip,omitempty"`
+	FirewallID    string          `yaml:"firewall_id,omitempty"`
+	Disks         []UHostDiskSpec `yaml:"disks"`
+}
+
+//UHostDiskSpec 云主机磁盘描述
+type UHostDiskSpec struct {
+	IsBoot     bool   `yaml:"is_boot"`
+	Type       string `yaml:"type"`
+	SizeGB     int    `yaml:"size_gb"`
+	BackupType string `yaml:"backup_type,omitempty"`
+}
+
+//VPCSpec VPC描述
+type VPCSpec struct {
+	ResourceID string       `yaml:"resource_id"`
+	Name       string       `yaml:"name"`
+	Group      string       `yaml:"group"`
+	Networks   []string     `yaml:"networks"`
+	Subnets    []SubnetSpec `yaml:"subnets,omitempty"`
+}
+
+//SubnetSpec 子网描述
+type SubnetSpec struct {
+	ResourceID string `yaml:"resource_id"`
+	Name       string `yaml:"name"`
+	Group      string `yaml:"group"`
+	CIDR       string `yaml:"cidr"`
+	Remark     string `yaml:"remark,omitempty"`
+}
+
+//FirewallSpec 防火墙描述, 规则格式 Protocol|Port|IP|Action|Level
+type FirewallSpec struct {
+	ResourceID string   `yaml:"resource_id"`
+	Name       string   `yaml:"name"`
+	Group      string   `yaml:"group"`
+	Remark     string   `yaml:"remark,omitempty"`
+	Rules      []string `yaml:"rules"`
+}
+
+//ULBSpec 负载均衡描述
+type ULBSpec struct {
+	ResourceID string        `yaml:"resource_id"`
+	Name       string        `yaml:"name"`
+	Group      string        `yaml:"group"`
+	Remark     string        `yaml:"remark,omitempty"`
+	Mode       string        `yaml:"mode"`
+	VPCID      string        `yaml:"vpc_id,omitempty"`
+	SubnetID   string        `yaml:"subnet_id,omitempty"`
+	VServers   []VServerSpec `yaml:"vservers,omitempty"`
+}
+
+//VServerSpec VServer描述
+type VServerSpec struct {
+	ResourceID      string        `yaml:"resource_id"`
+	Name            string        `yaml:"name"`
+	Protocol        string        `yaml:"protocol"`
+	Port            int           `yaml:"port"`
+	ListenType      string        `yaml:"listen_type"`
+	Method          string        `yaml:"method"`
+	PersistenceType string        `yaml:"persistence_type"`
+	PersistenceInfo string        `yaml:"persistence_info,omitempty"`
+	ClientTimeout   int           `yaml:"client_timeout"`
+	HealthCheckType string        `yaml:"health_check_type"`
+	Domain          string        `yaml:"domain,omitempty"`
+	Path            string        `yaml:"path,omitempty"`
+	Backends        []BackendSpec `yaml:"backends,omitempty"`
+	Policies        []PolicySpec  `yaml:"policies,omitempty"`
+}
+
+//BackendSpec VServer后端节点描述
+type BackendSpec struct {
+	ResourceID   string `yaml:"resource_id"`
+	ResourceType string `yaml:"resource_type"`
+	ResourceName string `yaml:"resource_name"`
+	BackendID    string `yaml:"backend_id"`
+	Port         int    `yaml:"port"`
+	Enabled      bool   `yaml:"enabled"`
+	Weight       int    `yaml:"weight"`
+}
+
+//PolicySpec 内容转发规则描述
+type PolicySpec struct {
+	ResourceID string   `yaml:"resource_id"`
+	Type       string   `yaml:"type"`
+	Match      string   `yaml:"match"`
+	Priority   int      `yaml:"priority"`
+	BackendIDs []string `yaml:"backend_ids"`
+}
+
+//UDBParamGroupSpec 数据库配置文件描述
+type UDBParamGroupSpec struct {
+	GroupID     int               `yaml:"group_id"`
+	Name        string            `yaml:"name"`
+	Zone        string            `yaml:"zone,omitempty"`
+	DBVersion   string            `yaml:"db_version"`
+	Description string            `yaml:"description,omitempty"`
+	Params      map[string]string `yaml:"params"`
+}
+
+func buildExportSpec(types []string, project, region, zone string) (*ExportSpec, error) {
+	spec := &ExportSpec{
+		ProjectID: project,
+		Region:    region,
+	}
+	for _, t := range types {
+		var err error
+		switch t {
+		case "uhost":
+			err = exportUHosts(spec, project, region, zone)
+		case "vpc":
+			err = exportVPCs(spec, project, region)
+		case "firewall":
+			err = exportFirewalls(spec, project, region)
+		case "ulb":
+			err = exportULBs(spec, project, region)
+		case "udb":
+			err = exportUDBParamGroups(spec, project, region, zone)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export %s failed: %v", t, err)
+		}
+	}
+	return spec, nil
+}
+
+func exportUHosts(spec *ExportSpec, project, region, zone string) error {
+	req := base.BizClient.NewDescribeUHostInstanceRequest()
+	req.ProjectId = &project
+	req.Region = &region
+	req.Zone = &zone
+	uhosts, err := getAllUHosts(req, true, false)
+	if err != nil {
+		return err
+	}
+	firewalls, err := getAllFirewallIns(project, region)
+	if err != nil {
+		return err
+	}
+	hostFirewall := make(map[string]string)
+	for _, fw := range firewalls {
+		if fw.ResourceCount == 0 {
+			continue
+		}
+		resources, err := getAllFirewallResources(fw.FWId, project, region)
+		if err != nil {
+			return err
+		}
+		for _, rs := range resources {
+			hostFirewall[rs] = fw.FWId
+		}
+	}
+	for _, host := range uhosts {
+		spec.UHosts = append(spec.UHosts, toUHostSpec(host, hostFirewall[host.UHostId]))
+		spec.Imports = append(spec.Imports, ExportImportCommand{
+			Address:    "ucloud_instance." + hclName(host.UHostId),
+			ResourceID: host.UHostId,
+		})
+	}
+	return nil
+}
+
+func toUHostSpec(host uhost.UHostInstanceSet, firewallID string) UHostSpec {
+	s := UHostSpec{
+		ResourceID:    host.UHostId,
+		Name:          host.Name,
+		Group:         host.Tag,
+		Zone:          host.Zone,
+		ImageID:       host.BasicImageId,
+		CPU:           host.CPU,
+		MemoryGB:      host.Memory / 1024,
+		MachineType:   host.MachineType,
+		ChargeType:    host.ChargeType,
+		NetCapability: host.NetCapability,
+		FirewallID:    firewallID,
+	}
+	for _, ip := range host.IPSet {
+		if ip.Type == "Private" {
+			s.VPCID = ip.VPCId
+			s.SubnetID = ip.SubnetId
+			s.PrivateIP = ip.IP
+		}
+	}
+	for _, disk := range host.DiskSet {
+		if disk.Type == "Udisk" {
+			continue
+		}
+		d := UHostDiskSpec{
+			IsBoot: disk.IsBoot == "True",
+			Type:   disk.DiskType,
+			SizeGB: disk.Size,
+		}
+		if disk.BackupType != "NONE" {
+			d.BackupType = disk.BackupType
+		}
+		s.Disks = append(s.Disks, d)
+	}
+	return s
+}
+
+func getAllFirewallResources(fwID, project, region string) ([]string, error) {
+	req := base.BizClient.NewDescribeFirewallResourceRequest()
+	req.FWId = &fwID
+	req.ProjectId = &project
+	req.Region = &region
+	ids := []string{}
+	for offset, limit := 0, 50; ; offset += limit {
+		req.Offset = sdk.String(strconv.Itoa(offset))
+		req.Limit = sdk.String(strconv.Itoa(limit))
+		resp, err := base.BizClient.DescribeFirewallResource(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, rs := range resp.ResourceSet {
+			ids = append(ids, rs.ResourceID)
+		}
+		if resp.TotalCount <= offset+limit {
+			break
+		}
+	}
+	return ids, nil
+}
+
+func exportVPCs(spec *ExportSpec, project, region string) error {
+	vpcs, err := getAllVPCIns(project, region)
+	if err != nil {
+		return err
+	}
+	for _, v := range vpcs {
+		s := VPCSpec{
+			ResourceID: v.VPCId,
+			Name:       v.Name,
+			Group:      v.Tag,
+			Networks:   v.Network,
+		}
+		subnets, err := getAllSubnets(v.VPCId, project, region)
+		if err != nil {
+			return err
+		}
+		spec.Imports = append(spec.Imports, ExportImportCommand{
+			Address:    "ucloud_vpc." + hclName(v.VPCId),
+			ResourceID: v.VPCId,
+		})
+		for _, subnet := range subnets {
+			s.Subnets = append(s.Subnets, SubnetSpec{
+				ResourceID: subnet.SubnetId,
+				Name:       subnet.SubnetName,
+				Group:      subnet.Tag,
+				CIDR:       fmt.Sprintf("%s/%s", subnet.Subnet, subnet.Netmask),
+				Remark:     subnet.Remark,
+			})
+			spec.Imports = append(spec.Imports, ExportImportCommand{
+				Address:    "ucloud_subnet." + hclName(subnet.SubnetId),
+				ResourceID: subnet.SubnetId,
+			})
+		}
+		spec.VPCs = append(spec.VPCs, s)
+	}
+	return nil
+}
+
+func exportFirewalls(spec *ExportSpec, project, region string) error {
+	firewalls, err := getAllFirewallIns(project, region)
+	if err != nil {
+		return err
+	}
+	for _, fw := range firewalls {
+		s := FirewallSpec{
+			ResourceID: fw.FWId,
+			Name:       fw.Name,
+			Group:      fw.Tag,
+			Remark:     fw.Remark,
+		}
+		for _, r := range fw.Rule {
+			s.Rules = append(s.Rules, fmt.Sprintf("%s|%s|%s|%s|%s", r.ProtocolType, r.DstPort, r.SrcIP, r.RuleAction, r.Priority))
+		}
+		spec.Firewalls = append(spec.Firewalls, s)
+		spec.Imports = append(spec.Imports, ExportImportCommand{
+			Address:    "ucloud_security_group." + hclName(fw.FWId),
+			ResourceID: fw.FWId,
+		})
+	}
+	return nil
+}
+
+var healthCheckTypeMap = map[string]string{
+	"Port": "port",
+	"Path": "path",
+}
+
+func exportULBs(spec *ExportSpec, project, region string) error {
+	ulbs, err := getAllULB(project, region)
+	if err != nil {
+		return err
+	}
+	for _, lb := range ulbs {
+		s := ULBSpec{
+			ResourceID: lb.ULBId,
+			Name:       lb.Name,
+			Group:      lb.Tag,
+			Remark:     lb.Remark,
+			Mode:       "outer",
+			VPCID:      lb.VPCId,
+			SubnetID:   lb.SubnetId,
+		}
+		if lb.ULBType == "InnerMode" {
+			s.Mode = "inner"
+		}
+		spec.Imports = append(spec.Imports, ExportImportCommand{
+			Address:    "ucloud_lb." + hclName(lb.ULBId),
+			ResourceID: lb.ULBId,
+		})
+		for _, vs := range lb.VServerSet {
+			vspec := VServerSpec{
+				ResourceID:      vs.VServerId,
+				Name:            vs.VServerName,
+				Protocol:        vs.Protocol,
+				Port:            vs.FrontendPort,
+				ListenType:      vs.ListenType,
+				Method:          vs.Method,
+				PersistenceType: vs.PersistenceType,
+				PersistenceInfo: vs.PersistenceInfo,
+				ClientTimeout:   vs.ClientTimeout,
+				HealthCheckType: vs.MonitorType,
+				Domain:          vs.Domain,
+				Path:            vs.Path,
+			}
+			spec.Imports = append(spec.Imports, ExportImportCommand{
+				Address:    "ucloud_lb_listener." + hclName(vs.VServerId),
+				ResourceID: fmt.Sprintf("%s/%s", lb.ULBId, vs.VServerId),
+			})
+			for _, b := range vs.BackendSet {
+				vspec.Backends = append(vspec.Backends, BackendSpec{
+					ResourceID:   b.ResourceId,
+					ResourceType: b.ResourceType,
+					ResourceName: b.ResourceName,
+					BackendID:    b.BackendId,
+					Port:         b.Port,
+					Enabled:      b.Enabled == 1,
+					Weight:       b.Weight,
+				})
+				spec.Imports = append(spec.Imports, ExportImportCommand{
+					Address:    "ucloud_lb_attachment." + hclName(b.BackendId),
+					ResourceID: fmt.Sprintf("%s/%s/%s", lb.ULBId, vs.VServerId, b.BackendId),
+				})
+			}
+			for _, p := range vs.PolicySet {
+				pspec := PolicySpec{
+					ResourceID: p.PolicyId,
+					Type:       p.Type,
+					Match:      p.Match,
+					Priority:   p.PolicyPriority,
+				}
+				for _, b := range p.BackendSet {
+					pspec.BackendIDs = append(pspec.BackendIDs, b.BackendId)
+				}
+				vspec.Policies = append(vspec.Policies, pspec)
+				spec.Imports = append(spec.Imports, ExportImportCommand{
+					Address:    "ucloud_lb_rule." + hclName(p.PolicyId),
+					ResourceID: fmt.Sprintf("%s/%s/%s", lb.ULBId, vs.VServerId, p.PolicyId),
+				})
+			}
+			s.VServers = append(s.VServers, vspec)
+		}
+		spec.ULBs = append(spec.ULBs, s)
+	}
+	return nil
+}
+
+func exportUDBParamGroups(spec *ExportSpec, project, region, zone string) error {
+	for _, dbType := range dbTypeList {
+		confs, err := getConfList(dbTypeMap[dbType], project, region, zone)
+		if err != nil {
+			return err
+		}
+		for _, conf := range confs {
+			//只导出用户自定义的配置文件, 默认配置文件不可修改
+			if !conf.Modifiable {
+				continue
+			}
+			s := UDBParamGroupSpec{
+				GroupID:     conf.GroupId,
+				Name:        conf.GroupName,
+				Zone:        conf.Zone,
+				DBVersion:   conf.DBTypeId,
+				Description: conf.Description,
+				Params:      make(map[string]string),
+			}
+			for _, p := range conf.ParamMember {
+				if p.Modifiable {
+					s.Params[p.Key] = p.Value
+				}
+			}
+			spec.UDBParamGroups = append(spec.UDBParamGroups, s)
+		}
+	}
+	return nil
+}
+
+var hclNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+//hclName 把资源ID转换为合法的Terraform资源名, uhost-xxx => uhost_xxx
+func hclName(resourceID string) string {
+	return hclNameRegexp.ReplaceAllString(resourceID, "_")
+}
+
+type hclWriter struct {
+	buf    bytes.Buffer
+	indent int
+}
+
+func (w *hclWriter) line(format string, a ...interface{}) {
+	w.buf.WriteString(strings.Repeat("  ", w.indent))
+	fmt.Fprintf(&w.buf, format, a...)
+	w.buf.WriteString("\n")
+}
+
+func (w *hclWriter) open(header string) {
+	w.line("%s {", header)
+	w.indent++
+}
+
+func (w *hclWriter) close() {
+	w.indent--
+	w.line("}")
+}
+
+func (w *hclWriter) attr(key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+		w.line("%s = %q", key, v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		w.line("%s = [%s]", key, strings.Join(quoted, ", "))
+	default:
+		w.line("%s = %v", key, v)
+	}
+}
+
+//ref 引用其他资源的属性, 若被引用资源未导出则使用资源ID
+func (w *hclWriter) ref(key, resourceType, resourceID string, exported map[string]bool) {
+	if resourceID == "" {
+		return
+	}
+	if exported[resourceID] {
+		w.line("%s = %s.%s.id", key, resourceType, hclName(resourceID))
+	} else {
+		w.attr(key, resourceID)
+	}
+}
+
+func renderExportHCL(spec *ExportSpec) []byte {
+	w := &hclWriter{}
+	//监听器等资源以 ulb-xxx/vserver-xxx 的形式导入, 按最后一段的资源ID记录
+	exported := make(map[string]bool)
+	for _, cmd := range spec.Imports {
+		exported[cmd.ResourceID[strings.LastIndex(cmd.ResourceID, "/")+1:]] = true
+	}
+
+	w.open(`provider "ucloud"`)
+	w.attr("project_id", spec.ProjectID)
+	w.attr("region", spec.Region)
+	w.close()
+
+	for _, v := range spec.VPCs {
+		w.line("")
+		w.open(fmt.Sprintf(`resource "ucloud_vpc" %q`, hclName(v.ResourceID)))
+		w.attr("name", v.Name)
+		w.attr("tag", v.Group)
+		w.attr("cidr_blocks", v.Networks)
+		w.close()
+		for _, s := range v.Subnets {
+			w.line("")
+			w.open(fmt.Sprintf(`resource "ucloud_subnet" %q`, hclName(s.ResourceID)))
+			w.attr("name", s.Name)
+			w.attr("tag", s.Group)
+			w.attr("remark", s.Remark)
+			w.attr("cidr_block", s.CIDR)
+			w.ref("vpc_id", "ucloud_vpc", v.ResourceID, exported)
+			w.close()
+		}
+	}
+
+	for _, fw := range spec.Firewalls {
+		w.line("")
+		w.open(fmt.Sprintf(`resource "ucloud_security_group" %q`, hclName(fw.ResourceID)))
+		w.attr("name", fw.Name)
+		w.attr("tag", fw.Group)
+		w.attr("remark", fw.Remark)
+		for _, rule := range fw.Rules {
+			fields := strings.Split(rule, "|")
+			if len(fields) != 5 {
+				w.line("# unrecognized rule: %s", rule)
+				continue
+			}
+			w.open("rules")
+			w.attr("protocol", strings.ToLower(fields[0]))
+			w.attr("port_range", fields[1])
+			w.attr("cidr_block", fields[2])
+			w.attr("policy", strings.ToLower(fields[3]))
+			w.attr("priority", strings.ToLower(fields[4]))
+			w.close()
+		}
+		w.close()
+	}
+
+	for _, h := range spec.UHosts {
+		w.line("")
+		w.open(fmt.Sprintf(`resource "ucloud_instance" %q`, hclName(h.ResourceID)))
+		w.attr("name", h.Name)
+		w.attr("tag", h.Group)
+		w.attr("availability_zone", h.Zone)
+		w.attr("image_id", h.ImageID)
+		machineType := strings.ToLower(h.MachineType)
+		if machineType == "" {
+			machineType = "n"
+		}
+		w.attr("instance_type", fmt.Sprintf("%s-customized-%d-%d", machineType, h.CPU, h.MemoryGB))
+		w.attr("charge_type", strings.ToLower(h.ChargeType))
+		w.ref("vpc_id", "ucloud_vpc", h.VPCID, exported)
+		w.ref("subnet_id", "ucloud_subnet", h.SubnetID, exported)
+		w.attr("private_ip", h.PrivateIP)
+		w.ref("security_group", "ucloud_security_group", h.FirewallID, exported)
+		extraDisks := []UHostDiskSpec{}
+		hasDataDisk := false
+		for _, d := range h.Disks {
+			if d.IsBoot {
+				w.attr("boot_disk_size", d.SizeGB)
+				w.attr("boot_disk_type", strings.ToLower(d.Type))
+			} else if !hasDataDisk {
+				hasDataDisk = true
+				w.attr("data_disk_size", d.SizeGB)
+				w.attr("data_disk_type", strings.ToLower(d.Type))
+			} else {
+				extraDisks = append(extraDisks, d)
+			}
+		}
+		w.line("# root_password is not exported, set it before applying")
+		w.close()
+		//ucloud_instance 只支持一块数据盘, 其余数据盘以注释的形式给出
+		for i, d := range extraDisks {
+			name := fmt.Sprintf("%s_data_%d", hclName(h.ResourceID), i+2)
+			w.line("")
+			w.line("# data disk %d of %s, import the existing udisk before uncommenting", i+2, h.ResourceID)
+			w.line(`# resource "ucloud_disk" %q {`, name)
+			w.line("#   availability_zone = %q", h.Zone)
+			w.line("#   name              = %q", fmt.Sprintf("%s-data-%d", h.Name, i+2))
+			w.line("#   disk_size         = %d", d.SizeGB)
+			w.line("#   disk_type         = %q", hclDiskType(d.Type))
+			w.line("# }")
+			w.line(`# resource "ucloud_disk_attachment" %q {`, name)
+			w.line("#   availability_zone = %q", h.Zone)
+			w.line("#   disk_id           = ucloud_disk.%s.id", name)
+			w.line("#   instance_id       = ucloud_instance.%s.id", hclName(h.ResourceID))
+			w.line("# }")
+		}
+	}
+
+	for _, lb := range spec.ULBs {
+		w.line("")
+		w.open(fmt.Sprintf(`resource "ucloud_lb" %q`, hclName(lb.ResourceID)))
+		w.attr("name", lb.Name)
+		w.attr("tag", lb.Group)
+		w.attr("remark", lb.Remark)
+		w.attr("internal", lb.Mode == "inner")
+		w.ref("vpc_id", "ucloud_vpc", lb.VPCID, exported)
+		w.ref("subnet_id", "ucloud_subnet", lb.SubnetID, exported)
+		w.close()
+		for _, vs := range lb.VServers {
+			w.line("")
+			w.open(fmt.Sprintf(`resource "ucloud_lb_listener" %q`, hclName(vs.ResourceID)))
+			w.ref("load_balancer_id", "ucloud_lb", lb.ResourceID, exported)
+			w.attr("name", vs.Name)
+			w.attr("protocol", strings.ToLower(vs.Protocol))
+			w.attr("port", vs.Port)
+			w.attr("listen_type", hclListenType(vs.ListenType))
+			w.attr("method", hclMethod(vs.Method))
+			w.attr("persistence_type", strings.ToLower(vs.PersistenceType))
+			w.attr("persistence", vs.PersistenceInfo)
+			w.attr("idle_timeout", vs.ClientTimeout)
+			if t, ok := healthCheckTypeMap[vs.HealthCheckType]; ok {
+				w.attr("health_check_type", t)
+			}
+			w.attr("domain", vs.Domain)
+			w.attr("path", vs.Path)
+			w.close()
+			for _, b := range vs.Backends {
+				w.line("")
+				w.open(fmt.Sprintf(`resource "ucloud_lb_attachment" %q`, hclName(b.BackendID)))
+				w.ref("load_balancer_id", "ucloud_lb", lb.ResourceID, exported)
+				w.ref("listener_id", "ucloud_lb_listener", vs.ResourceID, exported)
+				if b.ResourceType == "UHost" {
+					w.ref("resource_id", "ucloud_instance", b.ResourceID, exported)
+				} else {
+					w.attr("resource_id", b.ResourceID)
+				}
+				w.attr("port", b.Port)
+				w.close()
+			}
+			for _, p := range vs.Policies {
+				w.line("")
+				w.open(fmt.Sprintf(`resource "ucloud_lb_rule" %q`, hclName(p.ResourceID)))
+				w.ref("load_balancer_id", "ucloud_lb", lb.ResourceID, exported)
+				w.ref("listener_id", "ucloud_lb_listener", vs.ResourceID, exported)
+				backendRefs := make([]string, 0, len(p.BackendIDs))
+				for _, id := range p.BackendIDs {
+					if exported[id] {
+						backendRefs = append(backendRefs, fmt.Sprintf("ucloud_lb_attachment.%s.id", hclName(id)))
+					} else {
+						backendRefs = append(backendRefs, strconv.Quote(id))
+					}
+				}
+				w.line("backend_ids = [%s]", strings.Join(backendRefs, ", "))
+				if p.Type == "Domain" {
+					w.attr("domain", p.Match)
+				} else {
+					w.attr("path", p.Match)
+				}
+				w.close()
+			}
+		}
+	}
+
+	if len(spec.UDBParamGroups) > 0 {
+		w.line("")
+		w.line("# UDB param groups have no terraform resource, they are listed for reference only.")
+		for _, g := range spec.UDBParamGroups {
+			w.line("# %d/%s(%s)", g.GroupID, g.Name, g.DBVersion)
+			keys := make([]string, 0, len(g.Params))
+			for k := range g.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				w.line("#   %s = %s", k, g.Params[k])
+			}
+		}
+	}
+
+	if len(spec.Imports) > 0 {
+		w.line("")
+		w.line("# Import existing resources into terraform state:")
+		for _, cmd := range spec.Imports {
+			w.line("#   terraform import %s %s", cmd.Address, cmd.ResourceID)
+		}
+	}
+	return w.buf.Bytes()
+}
+
+func hclDiskType(diskType string) string {
+	switch diskType {
+	case "CLOUD_SSD":
+		return "ssd_data_disk"
+	case "CLOUD_RSSD":
+		return "rssd_data_disk"
+	}
+	return "data_disk"
+}
+
+func hclListenType(listenType string) string {
+	if listenType == "PacketsTransmit" {
+		return "packets_transmit"
+	}
+	return "request_proxy"
+}
+
+func hclMethod(method string) string {
+	switch method {
+	case "ConsistentHash":
+		return "consistent_hash"
+	case "SourcePort":
+		return "source_port"
+	case "ConsistentHashPort":
+		return "consistent_hash_port"
+	case "WeightRoundrobin":
+		return "weight_roundrobin"
+	case "Leastconn":
+		return "leastconn"
+	case "Source":
+		return "source"
+	}
+	return "roundrobin"
+}
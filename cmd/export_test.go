@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+type hclNameTest struct {
+	resourceID   string
+	expectedName string
+}
+
+func (test *hclNameTest) run(t *testing.T) {
+	name := hclName(test.resourceID)
+	if name != test.expectedName {
+		t.Errorf("hclName(%q), expected %q, got %q", test.resourceID, test.expectedName, name)
+	}
+}
+
+func TestHCLName(t *testing.T) {
+	tests := []hclNameTest{
+		{resourceID: "uhost-abc12", expectedName: "uhost_abc12"},
+		{resourceID: "ulb-1/vserver-2", expectedName: "ulb_1_vserver_2"},
+		{resourceID: "firewall_1", expectedName: "firewall_1"},
+		{resourceID: "backend-x.y", expectedName: "backend_x_y"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type renderExportHCLTest struct {
+	name        string
+	spec        *ExportSpec
+	contains    []string
+	notContains []string
+}
+
+func (test *renderExportHCLTest) run(t *testing.T) {
+	content := string(renderExportHCL(test.spec))
+	for _, s := range test.contains {
+		if !strings.Contains(content, s) {
+			t.Errorf("renderExportHCL %s, expected %q in output:\n%s", test.name, s, content)
+		}
+	}
+	for _, s := range test.notContains {
+		if strings.Contains(content, s) {
+			t.Errorf("renderExportHCL %s, unexpected %q in output:\n%s", test.name, s, content)
+		}
+	}
+}
+
+func TestRenderExportHCL(t *testing.T) {
+	tests := []renderExportHCLTest{
+		{
+			name: "uhost with multiple data disks",
+			spec: &ExportSpec{
+				ProjectID: "org-1",
+				Region:    "cn-bj2",
+				UHosts: []UHostSpec{{
+					ResourceID: "uhost-1",
+					Name:       "web",
+					Zone:       "cn-bj2-02",
+					CPU:        2,
+					MemoryGB:   4,
+					ChargeType: "Month",
+					VPCID:      "uvnet-1",
+					Disks: []UHostDiskSpec{
+						{IsBoot: true, Type: "CLOUD_SSD", SizeGB: 20},
+						{Type: "CLOUD_SSD", SizeGB: 100},
+						{Type: "CLOUD_RSSD", SizeGB: 200},
+					},
+				}},
+			},
+			contains: []string{
+				`resource "ucloud_instance" "uhost_1" {`,
+				`instance_type = "n-customized-2-4"`,
+				`vpc_id = "uvnet-1"`,
+				`data_disk_size = 100`,
+				`# resource "ucloud_disk" "uhost_1_data_2" {`,
+				`#   disk_type         = "rssd_data_disk"`,
+				`#   instance_id       = ucloud_instance.uhost_1.id`,
+			},
+			notContains: []string{
+				`data_disk_size = 200`,
+			},
+		},
+		{
+			name: "ulb with exported and unexported backends",
+			spec: &ExportSpec{
+				ULBs: []ULBSpec{{
+					ResourceID: "ulb-1",
+					Mode:       "outer",
+					VServers: []VServerSpec{{
+						ResourceID: "vserver-1",
+						Protocol:   "HTTP",
+						Port:       80,
+						Backends:   []BackendSpec{{ResourceID: "uhost-1", ResourceType: "UHost", BackendID: "backend-1", Port: 80}},
+						Policies:   []PolicySpec{{ResourceID: "policy-1", Type: "Domain", Match: "a.com", BackendIDs: []string{"backend-1", "backend-2"}}},
+					}},
+				}},
+				Imports: []ExportImportCommand{
+					{Address: "ucloud_lb.ulb_1", ResourceID: "ulb-1"},
+					{Address: "ucloud_lb_listener.vserver_1", ResourceID: "ulb-1/vserver-1"},
+					{Address: "ucloud_lb_attachment.backend_1", ResourceID: "ulb-1/vserver-1/backend-1"},
+				},
+			},
+			contains: []string{
+				`listener_id = ucloud_lb_listener.vserver_1.id`,
+				`resource_id = "uhost-1"`,
+				`backend_ids = [ucloud_lb_attachment.backend_1.id, "backend-2"]`,
+				`domain = "a.com"`,
+				`#   terraform import ucloud_lb_listener.vserver_1 ulb-1/vserver-1`,
+			},
+			notContains: []string{
+				`ucloud_lb_attachment.backend_2.id`,
+			},
+		},
+		{
+			name: "firewall rules",
+			spec: &ExportSpec{
+				Firewalls: []FirewallSpec{{
+					ResourceID: "firewall-1",
+					Rules:      []string{"TCP|22|0.0.0.0/0|ACCEPT|HIGH", "ESP||0.0.0.0/0|ACCEPT|LOW|remark"},
+				}},
+			},
+			contains: []string{
+				`protocol = "tcp"`,
+				`port_range = "22"`,
+				`# unrecognized rule: ESP||0.0.0.0/0|ACCEPT|LOW|remark`,
+			},
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -15,11 +15,8 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -47,6 +44,9 @@ func NewCmdFirewall() *cobra.Command {
 	cmd.AddCommand(NewCmdFirewallDelete())
 	cmd.AddCommand(NewCmdFirewallResource(writer))
 	cmd.AddCommand(NewCmdFirewallUpdate(writer))
+	cmd.AddCommand(NewCmdFirewallLint(writer))
+	cmd.AddCommand(NewCmdFirewallSync(writer))
+	cmd.AddCommand(NewCmdFirewallExport(writer))
 
 	return cmd
 }
@@ -108,23 +108,6 @@ func NewCmdFirewallList(out io.Writer) *cobra.Command {
 	return cmd
 }
 
-func parseRulesFromFile(filePath string) ([]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	lines := []string{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	return lines, nil
-}
-
 //NewCmdFirewallCreate ucloud firewall create
 func NewCmdFirewallCreate(out io.Writer) *cobra.Command {
 	var rulesFilePath string
@@ -141,15 +124,12 @@ func NewCmdFirewallCreate(out io.Writer) *cobra.Command {
 				fmt.Fprintln(out, "Error: flags rules and rules-file can't be both empty")
 				return
 			}
-			if rulesFilePath != "" {
-				lines, err := parseRulesFromFile(rulesFilePath)
-				if err != nil {
-					base.HandleError(err)
-					return
-				}
-				rules = append(rules, lines...)
+			list, err := collectFirewallRules(rules, rulesFilePath)
+			if err != nil {
+				base.HandleError(err)
+				return
 			}
-			req.Rule = rules
+			req.Rule = firewallRuleStrings(list)
 			resp, err := base.BizClient.CreateFirewall(req)
 			if err != nil {
 				base.HandleError(err)
@@ -161,7 +141,7 @@ func NewCmdFirewallCreate(out io.Writer) *cobra.Command {
 	flags := cmd.Flags()
 	flags.SortFlags = false
 	flags.StringSliceVar(&rules, "rules", nil, "Required if rules-file doesn't exist. Schema: Protocol|Port|IP|Action|Level. Prototol range 'TCP','UDP','ICMP' and 'GRE'; Port is a local port accessed by source address, port range [0-65535]; IP is the source address of the network packet that requests ucloud host resource, supporting IP address and network segment, such as '120.132.69.216' or '0.0.0.0/0'; Action is the processing behavior of the packet when the firewall is in effect, including 'ACCEPT' AND 'DROP'; Level, when a rule is added to a firewall, the rules take effect in order of level, which range 'HIGH','MEDIUM' and 'LOW'. For example, 'TCP|22|192.168.1.1/22|DROP|LOW'")
-	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if rules doesn't exist. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level.")
+	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if rules doesn't exist. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level[|Remark]. Blank lines and comments starting with '#' are ignored.")
 	req.Name = flags.String("name", "", "Required. Name of firewall to create")
 	req.Region = flags.String("region", base.ConfigIns.Region, "Optional. Region, see 'ucloud region'")
 	req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "Optional. Project-id, see 'ucloud project list'")
@@ -189,6 +169,11 @@ func NewCmdFirewallAddRule(out io.Writer) *cobra.Command {
 				fmt.Fprintln(out, "Error: flags rules and rules-file can't be both empty")
 				return
 			}
+			addRules, err := collectFirewallRules(req.Rule, rulesFilePath)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
 			for _, fwID := range fwIDs {
 				id := base.PickResourceID(fwID)
 				req.FWId = &id
@@ -197,27 +182,14 @@ func NewCmdFirewallAddRule(out io.Writer) *cobra.Command {
 					base.HandleError(err)
 					return
 				}
-				ruleMap := map[string]bool{}
-				for _, r := range firewall.Rule {
-					ruleStr := fmt.Sprintf("%s|%s|%s|%s|%s", r.ProtocolType, r.DstPort, r.SrcIP, r.RuleAction, r.Priority)
-					ruleMap[ruleStr] = true
-				}
-				if rulesFilePath != "" {
-					rules, err := parseRulesFromFile(rulesFilePath)
-					if err != nil {
-						base.HandleError(err)
-						return
-					}
-					req.Rule = append(req.Rule, rules...)
-				}
-				for _, r := range req.Rule {
-					ruleMap[r] = true
-				}
-				req.Rule = []string{}
-				for r := range ruleMap {
-					r = strings.TrimSpace(r)
-					req.Rule = append(req.Rule, r)
+				rules := currentFirewallRules(firewall)
+				count := len(rules)
+				rules = dedupFirewallRules(append(rules, addRules...))
+				if len(rules) == count {
+					base.Cxt.Printf("firewall[%s] unchanged, rules exist already\n", fwID)
+					continue
 				}
+				req.Rule = firewallRuleStrings(rules)
 				_, err = base.BizClient.UpdateFirewall(req)
 				if err != nil {
 					base.HandleError(err)
@@ -232,7 +204,7 @@ func NewCmdFirewallAddRule(out io.Writer) *cobra.Command {
 
 	flags.StringSliceVar(&fwIDs, "fw-id", nil, "Required. Resource ID of firewalls to update")
 	flags.StringSliceVar(&req.Rule, "rules", nil, "Required if rules-file is empay. Rules to add to firewall. Schema:'Protocol|Port|IP|Action|Level'. See 'ucloud firewall create --help' for detail.")
-	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if rules is empty. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level.")
+	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if rules is empty. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level[|Remark]. Blank lines and comments starting with '#' are ignored.")
 	req.Region = flags.String("region", base.ConfigIns.Region, "Optional. Region, see 'ucloud region'")
 	req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "Optional. Project-id, see 'ucloud project list'")
 
@@ -262,6 +234,15 @@ func NewCmdFirewallDeleteRule(out io.Writer) *cobra.Command {
 				fmt.Fprintln(out, "Error: flags rules and rules-file can't be both empty")
 				return
 			}
+			removeRules, err := collectFirewallRules(req.Rule, rulesFilePath)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			removeKeys := map[string]bool{}
+			for _, r := range removeRules {
+				removeKeys[r.Key()] = true
+			}
 			for _, fwID := range fwIDs {
 				id := base.PickResourceID(fwID)
 				req.FWId = &id
@@ -270,31 +251,22 @@ func NewCmdFirewallDeleteRule(out io.Writer) *cobra.Command {
 					base.HandleError(err)
 					return
 				}
-				ruleMap := map[string]bool{}
-				for _, r := range firewall.Rule {
-					ruleStr := fmt.Sprintf("%s|%s|%s|%s|%s", r.ProtocolType, r.DstPort, r.SrcIP, r.RuleAction, r.Priority)
-					ruleMap[ruleStr] = true
-				}
-				if rulesFilePath != "" {
-					rules, err := parseRulesFromFile(rulesFilePath)
-					if err != nil {
-						base.HandleError(err)
-						return
+				current := currentFirewallRules(firewall)
+				rules := []*firewallRule{}
+				for _, r := range current {
+					if !removeKeys[r.Key()] {
+						rules = append(rules, r)
 					}
-					req.Rule = append(req.Rule, rules...)
 				}
-				for _, r := range req.Rule {
-					r = strings.TrimSpace(r)
-					delete(ruleMap, r)
-				}
-				req.Rule = []string{}
-				for r := range ruleMap {
-					req.Rule = append(req.Rule, r)
+				if len(rules) == len(current) {
+					fmt.Fprintf(out, "firewall[%s] unchanged, rules not found\n", fwID)
+					continue
 				}
-				if len(req.Rule) == 0 {
+				if len(rules) == 0 {
 					fmt.Fprintf(out, "Error: rules can't be all deleted\n")
 					return
 				}
+				req.Rule = firewallRuleStrings(rules)
 				_, err = base.BizClient.UpdateFirewall(req)
 				if err != nil {
 					base.HandleError(err)
@@ -309,7 +281,7 @@ func NewCmdFirewallDeleteRule(out io.Writer) *cobra.Command {
 
 	flags.StringSliceVar(&fwIDs, "fw-id", nil, "Required. Resource ID of firewalls to update")
 	flags.StringSliceVar(&req.Rule, "rules", nil, "Required if rules-file is empay. Rules to add to firewall. Schema:'Protocol|Port|IP|Action|Level'. See 'ucloud firewall create --help' for detail.")
-	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if rules is empty. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level.")
+	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if rules is empty. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level[|Remark]. Blank lines and comments starting with '#' are ignored.")
 	req.Region = flags.String("region", base.ConfigIns.Region, "Optional. Region, see 'ucloud region'")
 	req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "Optional. Project-id, see 'ucloud project list'")
 
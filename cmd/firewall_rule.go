@@ -0,0 +1,479 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-sdk-go/services/unet"
+
+	"github.com/ucloud/ucloud-cli/base"
+)
+
+var firewallProtocols = []string{"TCP", "UDP", "ICMP", "GRE"}
+var firewallActions = []string{"ACCEPT", "DROP"}
+var firewallPriorities = []string{"HIGH", "MEDIUM", "LOW"}
+
+//firewallSensitivePorts 不应对 0.0.0.0/0 开放的端口
+var firewallSensitivePorts = map[int]string{
+	22:    "SSH",
+	3389:  "RDP",
+	3306:  "MySQL",
+	6379:  "Redis",
+	27017: "MongoDB",
+}
+
+//firewallRule 一条外网防火墙规则, Schema: Protocol|Port|IP|Action|Level[|Remark]
+type firewallRule struct {
+	Protocol string
+	PortFrom int
+	PortTo   int
+	SrcIP    string
+	Action   string
+	Priority string
+	Remark   string
+	//raw 无法解析的规则(如 API 返回的未知协议)原样保留
+	raw string
+}
+
+//parseFirewallRule 校验并规范化一条规则
+func parseFirewallRule(str string) (*firewallRule, error) {
+	fields := strings.Split(strings.TrimSpace(str), "|")
+	if len(fields) != 5 && len(fields) != 6 {
+		return nil, fmt.Errorf("rule %q should be Protocol|Port|IP|Action|Level[|Remark], accept %d fields", str, len(fields))
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	rule := &firewallRule{
+		Protocol: strings.ToUpper(fields[0]),
+		Action:   strings.ToUpper(fields[3]),
+		Priority: strings.ToUpper(fields[4]),
+	}
+	if len(fields) == 6 {
+		rule.Remark = fields[5]
+	}
+	if !containsString(firewallProtocols, rule.Protocol) {
+		return nil, fmt.Errorf("rule %q: protocol should be one of %s, accept %q", str, strings.Join(firewallProtocols, ","), fields[0])
+	}
+	if rule.hasPort() {
+		from, to, err := parseFirewallPort(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %v", str, err)
+		}
+		rule.PortFrom, rule.PortTo = from, to
+	} else if fields[1] != "" {
+		return nil, fmt.Errorf("rule %q: port should be empty for protocol %s", str, rule.Protocol)
+	}
+	srcIP, err := normalizeFirewallIP(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %v", str, err)
+	}
+	rule.SrcIP = srcIP
+	if !containsString(firewallActions, rule.Action) {
+		return nil, fmt.Errorf("rule %q: action should be one of %s, accept %q", str, strings.Join(firewallActions, ","), fields[3])
+	}
+	if !containsString(firewallPriorities, rule.Priority) {
+		return nil, fmt.Errorf("rule %q: level should be one of %s, accept %q", str, strings.Join(firewallPriorities, ","), fields[4])
+	}
+	return rule, nil
+}
+
+//parseFirewallPort 解析端口或端口段, 如 22 或 8000-8080
+func parseFirewallPort(str string) (int, int, error) {
+	parts := strings.Split(str, "-")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("invalid port %q", str)
+	}
+	ports := []int{}
+	for _, p := range parts {
+		port, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || port < 1 || port > 65535 {
+			return 0, 0, fmt.Errorf("port should be in range [1-65535] or a range like 8000-8080, accept %q", str)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	}
+	if ports[0] > ports[1] {
+		return 0, 0, fmt.Errorf("invalid port range %q, start is greater than end", str)
+	}
+	return ports[0], ports[1], nil
+}
+
+//normalizeFirewallIP 校验源地址, 单个IP的/32(/128)掩码会被去掉, 网段会对齐到网络地址
+func normalizeFirewallIP(str string) (string, error) {
+	if !strings.Contains(str, "/") {
+		ip := net.ParseIP(str)
+		if ip == nil {
+			return "", fmt.Errorf("invalid source ip %q", str)
+		}
+		return ip.String(), nil
+	}
+	_, ipNet, err := net.ParseCIDR(str)
+	if err != nil {
+		return "", fmt.Errorf("invalid source network segment %q", str)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if ones == bits {
+		return ipNet.IP.String(), nil
+	}
+	return ipNet.String(), nil
+}
+
+func (r *firewallRule) hasPort() bool {
+	return r.Protocol == "TCP" || r.Protocol == "UDP"
+}
+
+//Port 端口的字符串形式
+func (r *firewallRule) Port() string {
+	if !r.hasPort() {
+		return ""
+	}
+	if r.PortFrom == r.PortTo {
+		return strconv.Itoa(r.PortFrom)
+	}
+	return fmt.Sprintf("%d-%d", r.PortFrom, r.PortTo)
+}
+
+//Key 不含备注的规范化规则, 用于判断两条规则是否等价
+func (r *firewallRule) Key() string {
+	if r.raw != "" {
+		return r.raw
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s", r.Protocol, r.Port(), r.SrcIP, r.Action, r.Priority)
+}
+
+func (r *firewallRule) String() string {
+	if r.raw != "" {
+		return r.raw
+	}
+	if r.Remark != "" {
+		return r.Key() + "|" + r.Remark
+	}
+	return r.Key()
+}
+
+func (r *firewallRule) ipNet() *net.IPNet {
+	if strings.Contains(r.SrcIP, "/") {
+		_, ipNet, _ := net.ParseCIDR(r.SrcIP)
+		return ipNet
+	}
+	ip := net.ParseIP(r.SrcIP)
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
+
+func (r *firewallRule) isAnyIP() bool {
+	ones, _ := r.ipNet().Mask.Size()
+	return ones == 0
+}
+
+//covers 规则r匹配的流量是否包含规则o匹配的全部流量
+func (r *firewallRule) covers(o *firewallRule) bool {
+	if r.Protocol != o.Protocol {
+		return false
+	}
+	if r.hasPort() && (r.PortFrom > o.PortFrom || r.PortTo < o.PortTo) {
+		return false
+	}
+	rNet, oNet := r.ipNet(), o.ipNet()
+	rOnes, rBits := rNet.Mask.Size()
+	oOnes, oBits := oNet.Mask.Size()
+	return rBits == oBits && rOnes <= oOnes && rNet.Contains(oNet.IP)
+}
+
+func (r *firewallRule) priorityRank() int {
+	for i, p := range firewallPriorities {
+		if p == r.Priority {
+			return i
+		}
+	}
+	return len(firewallPriorities)
+}
+
+//parseFirewallRules 校验并规范化一组规则
+func parseFirewallRules(strs []string) ([]*firewallRule, error) {
+	rules := []*firewallRule{}
+	for _, str := range strs {
+		rule, err := parseFirewallRule(str)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+//stripRuleComment 去掉规则行中的注释. 行首的#或前面有空白的#开始注释, 备注字段中的#原样保留
+func stripRuleComment(line string) string {
+	end := len(line)
+	pipes := 0
+	for i, c := range line {
+		if c == '|' {
+			pipes++
+			if pipes == 5 {
+				end = i
+				break
+			}
+		}
+	}
+	for i := 0; i < end; i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+//parseRulesFromFile 读取规则文件, 每行一条规则, 忽略空行和#开头的注释, 备注字段之前的 # 注释也会被去掉
+func parseRulesFromFile(filePath string) ([]*firewallRule, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	rules := []*firewallRule{}
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(stripRuleComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		rule, err := parseFirewallRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", filePath, lineNo, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+//firewallRuleFromSet 把 API 返回的规则转换为 firewallRule, 无法解析时返回原样保留的规则和错误
+func firewallRuleFromSet(r unet.FirewallRuleSet) (*firewallRule, error) {
+	str := fmt.Sprintf("%s|%s|%s|%s|%s", r.ProtocolType, r.DstPort, r.SrcIP, r.RuleAction, r.Priority)
+	if r.Remark != "" {
+		str += "|" + r.Remark
+	}
+	rule, err := parseFirewallRule(str)
+	if err != nil {
+		return &firewallRule{raw: str}, err
+	}
+	return rule, nil
+}
+
+//collectFirewallRules 合并参数和规则文件中的规则, 并去重
+func collectFirewallRules(strs []string, filePath string) ([]*firewallRule, error) {
+	rules, err := parseFirewallRules(strs)
+	if err != nil {
+		return nil, err
+	}
+	if filePath != "" {
+		fileRules, err := parseRulesFromFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+	return dedupFirewallRules(rules), nil
+}
+
+//currentFirewallRules 防火墙当前的规则, 保持 API 返回的顺序
+func currentFirewallRules(firewall *unet.FirewallDataSet) []*firewallRule {
+	rules := []*firewallRule{}
+	for _, r := range firewall.Rule {
+		rule, _ := firewallRuleFromSet(r)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+//dedupFirewallRules 按规范化后的规则去重, 保留第一次出现的顺序
+func dedupFirewallRules(rules []*firewallRule) []*firewallRule {
+	seen := map[string]bool{}
+	list := []*firewallRule{}
+	for _, r := range rules {
+		if seen[r.Key()] {
+			continue
+		}
+		seen[r.Key()] = true
+		list = append(list, r)
+	}
+	return list
+}
+
+func firewallRuleStrings(rules []*firewallRule) []string {
+	list := []string{}
+	for _, r := range rules {
+		list = append(list, r.String())
+	}
+	return list
+}
+
+//FirewallLintRow 表格行
+type FirewallLintRow struct {
+	Rule     string
+	Severity string
+	Issue    string
+}
+
+//lintFirewallRules 检查重复、被遮蔽以及过于宽松的规则
+func lintFirewallRules(rules []*firewallRule) []FirewallLintRow {
+	list := []FirewallLintRow{}
+	for i, r := range rules {
+		for j := 0; j < len(rules); j++ {
+			o := rules[j]
+			if i == j {
+				continue
+			}
+			if r.Key() == o.Key() {
+				if j < i {
+					list = append(list, FirewallLintRow{r.String(), "WARNING", fmt.Sprintf("duplicate of rule #%d", j+1)})
+					break
+				}
+				continue
+			}
+			if !o.covers(r) {
+				continue
+			}
+			if o.priorityRank() < r.priorityRank() {
+				if o.Action == r.Action {
+					list = append(list, FirewallLintRow{r.String(), "WARNING", fmt.Sprintf("redundant, covered by higher level rule #%d %s", j+1, o.Key())})
+				} else {
+					list = append(list, FirewallLintRow{r.String(), "ERROR", fmt.Sprintf("shadowed, never takes effect because of higher level rule #%d %s", j+1, o.Key())})
+				}
+				break
+			}
+			if o.priorityRank() != r.priorityRank() {
+				continue
+			}
+			if o.Action != r.Action {
+				//两条规则匹配的流量完全相同时只在后一条规则上报告冲突
+				if r.covers(o) && j > i {
+					continue
+				}
+				list = append(list, FirewallLintRow{r.String(), "ERROR", fmt.Sprintf("conflicts with rule #%d %s of the same level", j+1, o.Key())})
+				break
+			}
+			if !r.covers(o) {
+				list = append(list, FirewallLintRow{r.String(), "WARNING", fmt.Sprintf("redundant, covered by rule #%d %s", j+1, o.Key())})
+				break
+			}
+		}
+		if r.Action != "ACCEPT" || !r.isAnyIP() {
+			continue
+		}
+		if !r.hasPort() {
+			continue
+		}
+		if r.PortFrom == 1 && r.PortTo == 65535 {
+			list = append(list, FirewallLintRow{r.String(), "WARNING", "all ports are open to the internet"})
+			continue
+		}
+		for port := r.PortFrom; port <= r.PortTo && port <= 65535; port++ {
+			if name, ok := firewallSensitivePorts[port]; ok {
+				list = append(list, FirewallLintRow{r.String(), "WARNING", fmt.Sprintf("%s port %d is open to the internet", name, port)})
+			}
+		}
+	}
+	return list
+}
+
+//NewCmdFirewallLint ucloud firewall lint
+func NewCmdFirewallLint(out io.Writer) *cobra.Command {
+	var fwID, rulesFilePath, project, region string
+	cmd := &cobra.Command{
+		Use:     "lint",
+		Short:   "Check firewall rules for duplicate, shadowed and overly permissive rules",
+		Long:    "Check rules of a firewall or a rules file for invalid, duplicate, shadowed and overly permissive rules",
+		Example: "ucloud firewall lint --fw-id firewall-xxx; ucloud firewall lint --rules-file firewall_rules.txt",
+		Run: func(c *cobra.Command, args []string) {
+			if (fwID == "") == (rulesFilePath == "") {
+				fmt.Fprintln(out, "Error: one of flags fw-id and rules-file should be assigned")
+				return
+			}
+			var rules []*firewallRule
+			list := []FirewallLintRow{}
+			if rulesFilePath != "" {
+				var err error
+				rules, err = parseRulesFromFile(rulesFilePath)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+			} else {
+				firewall, err := getFirewall(base.PickResourceID(fwID), project, region)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				for _, r := range firewall.Rule {
+					rule, err := firewallRuleFromSet(r)
+					if err != nil {
+						list = append(list, FirewallLintRow{rule.String(), "ERROR", err.Error()})
+						continue
+					}
+					rules = append(rules, rule)
+				}
+			}
+			list = append(list, lintFirewallRules(rules)...)
+			if len(list) == 0 {
+				fmt.Fprintf(out, "%d rules checked, no issue found\n", len(rules))
+				return
+			}
+			base.PrintList(list, out)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&fwID, "fw-id", "", "Required if rules-file is empty. Resource ID of firewall to check")
+	flags.StringVar(&rulesFilePath, "rules-file", "", "Required if fw-id is empty. Path of rules file to check. Schema: Protocol|Port|IP|Action|Level[|Remark]")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+
+	flags.SetFlagValuesFunc("fw-id", func() []string {
+		return getFirewallIDNames(project, region)
+	})
+	flags.SetFlagValuesFunc("rules-file", func() []string {
+		return base.GetFileList("")
+	})
+
+	return cmd
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustParseFirewallRule(t *testing.T, str string) *firewallRule {
+	rule, err := parseFirewallRule(str)
+	if err != nil {
+		t.Fatalf("parseFirewallRule(%q), unexpected error: %v", str, err)
+	}
+	return rule
+}
+
+type parseFirewallRuleTest struct {
+	str         string
+	expectedStr string
+	expectedErr string
+}
+
+func (test *parseFirewallRuleTest) run(t *testing.T) {
+	rule, err := parseFirewallRule(test.str)
+	if test.expectedErr != "" {
+		if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+			t.Errorf("parseFirewallRule(%q), expected error containing %q, got %v", test.str, test.expectedErr, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("parseFirewallRule(%q), unexpected error: %v", test.str, err)
+	}
+	if rule.String() != test.expectedStr {
+		t.Errorf("parseFirewallRule(%q), expected %q, got %q", test.str, test.expectedStr, rule.String())
+	}
+}
+
+func TestParseFirewallRule(t *testing.T) {
+	tests := []parseFirewallRuleTest{
+		{str: "TCP|22|0.0.0.0/0|ACCEPT|HIGH", expectedStr: "TCP|22|0.0.0.0/0|ACCEPT|HIGH"},
+		{str: " tcp | 8000-8080 | 10.0.0.1/32 | accept | low | web ", expectedStr: "TCP|8000-8080|10.0.0.1|ACCEPT|LOW|web"},
+		{str: "ICMP||10.0.1.7/24|DROP|MEDIUM", expectedStr: "ICMP||10.0.1.0/24|DROP|MEDIUM"},
+		{str: "UDP|53|0.0.0.0/0|ACCEPT|HIGH|dns #1", expectedStr: "UDP|53|0.0.0.0/0|ACCEPT|HIGH|dns #1"},
+		{str: "TCP|22|0.0.0.0/0|ACCEPT", expectedErr: "accept 4 fields"},
+		{str: "ESP||0.0.0.0/0|ACCEPT|HIGH", expectedErr: "protocol should be one of"},
+		{str: "ICMP|22|0.0.0.0/0|ACCEPT|HIGH", expectedErr: "port should be empty"},
+		{str: "TCP|0|0.0.0.0/0|ACCEPT|HIGH", expectedErr: "port should be in range"},
+		{str: "TCP|22|10.0.0.256|ACCEPT|HIGH", expectedErr: "invalid source ip"},
+		{str: "TCP|22|0.0.0.0/0|ALLOW|HIGH", expectedErr: "action should be one of"},
+		{str: "TCP|22|0.0.0.0/0|ACCEPT|URGENT", expectedErr: "level should be one of"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type parseFirewallPortTest struct {
+	str          string
+	expectedFrom int
+	expectedTo   int
+	expectedErr  bool
+}
+
+func (test *parseFirewallPortTest) run(t *testing.T) {
+	from, to, err := parseFirewallPort(test.str)
+	if test.expectedErr {
+		if err == nil {
+			t.Errorf("parseFirewallPort(%q), expected error, got %d-%d", test.str, from, to)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("parseFirewallPort(%q), unexpected error: %v", test.str, err)
+	}
+	if from != test.expectedFrom || to != test.expectedTo {
+		t.Errorf("parseFirewallPort(%q), expected %d-%d, got %d-%d", test.str, test.expectedFrom, test.expectedTo, from, to)
+	}
+}
+
+func TestParseFirewallPort(t *testing.T) {
+	tests := []parseFirewallPortTest{
+		{str: "22", expectedFrom: 22, expectedTo: 22},
+		{str: "8000-8080", expectedFrom: 8000, expectedTo: 8080},
+		{str: "1-65535", expectedFrom: 1, expectedTo: 65535},
+		{str: "", expectedErr: true},
+		{str: "0", expectedErr: true},
+		{str: "65536", expectedErr: true},
+		{str: "8080-8000", expectedErr: true},
+		{str: "1-2-3", expectedErr: true},
+		{str: "http", expectedErr: true},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type normalizeFirewallIPTest struct {
+	str         string
+	expectedIP  string
+	expectedErr bool
+}
+
+func (test *normalizeFirewallIPTest) run(t *testing.T) {
+	ip, err := normalizeFirewallIP(test.str)
+	if test.expectedErr {
+		if err == nil {
+			t.Errorf("normalizeFirewallIP(%q), expected error, got %q", test.str, ip)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("normalizeFirewallIP(%q), unexpected error: %v", test.str, err)
+	}
+	if ip != test.expectedIP {
+		t.Errorf("normalizeFirewallIP(%q), expected %q, got %q", test.str, test.expectedIP, ip)
+	}
+}
+
+func TestNormalizeFirewallIP(t *testing.T) {
+	tests := []normalizeFirewallIPTest{
+		{str: "10.0.0.1", expectedIP: "10.0.0.1"},
+		{str: "10.0.0.1/32", expectedIP: "10.0.0.1"},
+		{str: "10.0.0.9/24", expectedIP: "10.0.0.0/24"},
+		{str: "0.0.0.0/0", expectedIP: "0.0.0.0/0"},
+		{str: "2001:db8::1/128", expectedIP: "2001:db8::1"},
+		{str: "10.0.0.1/33", expectedErr: true},
+		{str: "localhost", expectedErr: true},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type coversTest struct {
+	rule     string
+	other    string
+	expected bool
+}
+
+func (test *coversTest) run(t *testing.T) {
+	r, o := mustParseFirewallRule(t, test.rule), mustParseFirewallRule(t, test.other)
+	if got := r.covers(o); got != test.expected {
+		t.Errorf("%q covers %q, expected %t, got %t", test.rule, test.other, test.expected, got)
+	}
+}
+
+func TestFirewallRuleCovers(t *testing.T) {
+	tests := []coversTest{
+		{rule: "TCP|1-65535|0.0.0.0/0|ACCEPT|HIGH", other: "TCP|22|10.0.0.1|DROP|LOW", expected: true},
+		{rule: "TCP|20-30|10.0.0.0/8|ACCEPT|HIGH", other: "TCP|22|10.1.0.0/16|ACCEPT|HIGH", expected: true},
+		{rule: "TCP|22|10.0.0.0/8|ACCEPT|HIGH", other: "TCP|20-30|10.1.0.0/16|ACCEPT|HIGH", expected: false},
+		{rule: "TCP|22|10.1.0.0/16|ACCEPT|HIGH", other: "TCP|22|10.0.0.0/8|ACCEPT|HIGH", expected: false},
+		{rule: "TCP|22|0.0.0.0/0|ACCEPT|HIGH", other: "UDP|22|0.0.0.0/0|ACCEPT|HIGH", expected: false},
+		{rule: "ICMP||0.0.0.0/0|ACCEPT|HIGH", other: "ICMP||10.0.0.1|DROP|HIGH", expected: true},
+		{rule: "TCP|22|0.0.0.0/0|ACCEPT|HIGH", other: "TCP|22|2001:db8::1|ACCEPT|HIGH", expected: false},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type lintFirewallRulesTest struct {
+	rules          []string
+	expectedIssues []string
+}
+
+func (test *lintFirewallRulesTest) run(t *testing.T) {
+	rules := []*firewallRule{}
+	for _, str := range test.rules {
+		rules = append(rules, mustParseFirewallRule(t, str))
+	}
+	list := lintFirewallRules(rules)
+	issues := []string{}
+	for _, row := range list {
+		issues = append(issues, row.Severity+": "+row.Issue)
+	}
+	if strings.Join(issues, "\n") != strings.Join(test.expectedIssues, "\n") {
+		t.Errorf("lintFirewallRules(%v), expected issues:\n%s\ngot:\n%s", test.rules, strings.Join(test.expectedIssues, "\n"), strings.Join(issues, "\n"))
+	}
+}
+
+func TestLintFirewallRules(t *testing.T) {
+	tests := []lintFirewallRulesTest{
+		{
+			rules:          []string{"TCP|80|10.0.0.0/8|ACCEPT|HIGH", "UDP|53|10.0.0.0/8|ACCEPT|HIGH"},
+			expectedIssues: []string{},
+		},
+		{
+			rules:          []string{"TCP|80|10.0.0.0/8|ACCEPT|HIGH", "TCP|80|10.0.0.0/8|ACCEPT|HIGH|dup"},
+			expectedIssues: []string{"WARNING: duplicate of rule #1"},
+		},
+		{
+			rules:          []string{"TCP|1-1024|10.0.0.0/8|DROP|HIGH", "TCP|80|10.1.0.0/16|ACCEPT|LOW"},
+			expectedIssues: []string{"ERROR: shadowed, never takes effect because of higher level rule #1 TCP|1-1024|10.0.0.0/8|DROP|HIGH"},
+		},
+		{
+			rules:          []string{"TCP|1-1024|10.0.0.0/8|ACCEPT|MEDIUM", "TCP|80|10.1.0.0/16|ACCEPT|MEDIUM"},
+			expectedIssues: []string{"WARNING: redundant, covered by rule #1 TCP|1-1024|10.0.0.0/8|ACCEPT|MEDIUM"},
+		},
+		{
+			rules:          []string{"TCP|1-1024|10.0.0.0/8|DROP|MEDIUM", "TCP|80|10.1.0.0/16|ACCEPT|MEDIUM"},
+			expectedIssues: []string{"ERROR: conflicts with rule #1 TCP|1-1024|10.0.0.0/8|DROP|MEDIUM of the same level"},
+		},
+		{
+			rules:          []string{"TCP|22|0.0.0.0/0|ACCEPT|HIGH", "TCP|22|0.0.0.0/0|DROP|HIGH"},
+			expectedIssues: []string{"WARNING: SSH port 22 is open to the internet", "ERROR: conflicts with rule #1 TCP|22|0.0.0.0/0|ACCEPT|HIGH of the same level"},
+		},
+		{
+			rules:          []string{"TCP|80|10.1.0.0/16|ACCEPT|LOW", "TCP|1-1024|10.0.0.0/8|DROP|LOW"},
+			expectedIssues: []string{"ERROR: conflicts with rule #2 TCP|1-1024|10.0.0.0/8|DROP|LOW of the same level"},
+		},
+		{
+			rules: []string{"TCP|22|0.0.0.0/0|ACCEPT|HIGH", "TCP|3300-3400|0.0.0.0/0|ACCEPT|HIGH", "UDP|1-65535|0.0.0.0/0|ACCEPT|LOW"},
+			expectedIssues: []string{
+				"WARNING: SSH port 22 is open to the internet",
+				"WARNING: MySQL port 3306 is open to the internet",
+				"WARNING: RDP port 3389 is open to the internet",
+				"WARNING: all ports are open to the internet",
+			},
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type stripRuleCommentTest struct {
+	line         string
+	expectedLine string
+}
+
+func (test *stripRuleCommentTest) run(t *testing.T) {
+	line := stripRuleComment(test.line)
+	if line != test.expectedLine {
+		t.Errorf("stripRuleComment(%q), expected %q, got %q", test.line, test.expectedLine, line)
+	}
+}
+
+func TestStripRuleComment(t *testing.T) {
+	tests := []stripRuleCommentTest{
+		{line: "# firewall[firewall-1]", expectedLine: ""},
+		{line: "TCP|22|0.0.0.0/0|ACCEPT|HIGH # ssh", expectedLine: "TCP|22|0.0.0.0/0|ACCEPT|HIGH "},
+		{line: "TCP|22|0.0.0.0/0|ACCEPT|HIGH|ticket #42", expectedLine: "TCP|22|0.0.0.0/0|ACCEPT|HIGH|ticket #42"},
+		{line: "TCP|22|0.0.0.0/0|ACCEPT|HIGH|#42", expectedLine: "TCP|22|0.0.0.0/0|ACCEPT|HIGH|#42"},
+		{line: "TCP|22#x|0.0.0.0/0|ACCEPT|HIGH", expectedLine: "TCP|22#x|0.0.0.0/0|ACCEPT|HIGH"},
+		{line: "\t# indented comment", expectedLine: "\t"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+func TestParseRulesFromFileRemarkRoundTrip(t *testing.T) {
+	rules, err := parseFirewallRules([]string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH|ticket #42", "UDP|53|10.0.0.0/8|ACCEPT|LOW"})
+	if err != nil {
+		t.Fatalf("parseFirewallRules, unexpected error: %v", err)
+	}
+	file, err := ioutil.TempFile("", "firewall_rules")
+	if err != nil {
+		t.Fatalf("create temp file failed: %v", err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("# exported rules\n\n")
+	for _, r := range rules {
+		file.WriteString(r.String() + "\n")
+	}
+	file.WriteString("TCP|443|0.0.0.0/0|ACCEPT|HIGH # https\n")
+	file.Close()
+
+	parsed, err := parseRulesFromFile(file.Name())
+	if err != nil {
+		t.Fatalf("parseRulesFromFile, unexpected error: %v", err)
+	}
+	expected := []string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH|ticket #42", "UDP|53|10.0.0.0/8|ACCEPT|LOW", "TCP|443|0.0.0.0/0|ACCEPT|HIGH"}
+	if got := firewallRuleStrings(parsed); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseRulesFromFile, expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//diffFirewallRules 比较当前规则和期望规则, 备注不同也视为不同的规则
+func diffFirewallRules(current, desired []*firewallRule) (added, removed []*firewallRule) {
+	currentSet := map[string]bool{}
+	for _, r := range current {
+		currentSet[r.String()] = true
+	}
+	desiredSet := map[string]bool{}
+	for _, r := range desired {
+		desiredSet[r.String()] = true
+		if !currentSet[r.String()] {
+			added = append(added, r)
+		}
+	}
+	for _, r := range current {
+		if !desiredSet[r.String()] {
+			removed = append(removed, r)
+		}
+	}
+	return
+}
+
+//NewCmdFirewallSync ucloud firewall sync
+func NewCmdFirewallSync(out io.Writer) *cobra.Command {
+	var fwID, rulesFilePath, project, region string
+	var dryRun, yes bool
+	cmd := &cobra.Command{
+		Use:     "sync",
+		Short:   "Make rules of the firewall exactly match a rules file",
+		Long:    "Make rules of the firewall exactly match a rules file. Rules not in the file are removed and rules only in the file are added. The diff is printed before updating",
+		Example: "ucloud firewall sync --fw-id firewall-xxx --rules-file firewall_rules.txt --dry-run",
+		Run: func(c *cobra.Command, args []string) {
+			desired, err := parseRulesFromFile(rulesFilePath)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			desired = dedupFirewallRules(desired)
+			if len(desired) == 0 {
+				fmt.Fprintf(out, "Error: no rule found in %s, rules can't be all deleted\n", rulesFilePath)
+				return
+			}
+			firewall, err := getFirewall(base.PickResourceID(fwID), project, region)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			added, removed := diffFirewallRules(currentFirewallRules(firewall), desired)
+			if len(added) == 0 && len(removed) == 0 {
+				fmt.Fprintf(out, "firewall[%s] is up to date\n", firewall.FWId)
+				return
+			}
+			for _, r := range removed {
+				fmt.Fprintf(out, "- %s\n", r)
+			}
+			for _, r := range added {
+				fmt.Fprintf(out, "+ %s\n", r)
+			}
+			fmt.Fprintf(out, "%d to add, %d to remove\n", len(added), len(removed))
+			if dryRun {
+				return
+			}
+			if !yes {
+				sure, err := ux.Prompt(fmt.Sprintf("Are you sure you want to update rules of firewall[%s]?", firewall.FWId))
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			req := base.BizClient.NewUpdateFirewallRequest()
+			req.ProjectId = sdk.String(project)
+			req.Region = sdk.String(region)
+			req.FWId = sdk.String(firewall.FWId)
+			req.Rule = firewallRuleStrings(desired)
+			_, err = base.BizClient.UpdateFirewall(req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			fmt.Fprintf(out, "firewall[%s] synced\n", firewall.FWId)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&fwID, "fw-id", "", "Required. Resource ID of firewall to sync")
+	flags.StringVar(&rulesFilePath, "rules-file", "", "Required. Path of rules file, in which each rule occupies one line. Schema: Protocol|Port|IP|Action|Level[|Remark]. Blank lines and comments starting with '#' are ignored, '#' in the remark field is kept.")
+	flags.BoolVar(&dryRun, "dry-run", false, "Optional. Print the diff only, do not update the firewall")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation.")
+
+	flags.SetFlagValuesFunc("fw-id", func() []string {
+		return getFirewallIDNames(project, region)
+	})
+	flags.SetFlagValuesFunc("rules-file", func() []string {
+		return base.GetFileList("")
+	})
+	flags.SetFlagValues("dry-run", "true", "false")
+
+	cmd.MarkFlagRequired("fw-id")
+	cmd.MarkFlagRequired("rules-file")
+
+	return cmd
+}
+
+//NewCmdFirewallExport ucloud firewall export
+func NewCmdFirewallExport(out io.Writer) *cobra.Command {
+	var fwID, output, project, region string
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Export rules of the firewall as a rules file",
+		Long:    "Export rules of the firewall in the rules file format accepted by 'ucloud firewall sync'",
+		Example: "ucloud firewall export --fw-id firewall-xxx --output firewall_rules.txt",
+		Run: func(c *cobra.Command, args []string) {
+			firewall, err := getFirewall(base.PickResourceID(fwID), project, region)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "# firewall[%s] %s, region %s\n", firewall.FWId, firewall.Name, region)
+			fmt.Fprintf(&buf, "# exported at %s\n", time.Now().Format(time.RFC3339))
+			fmt.Fprintln(&buf, "# Schema: Protocol|Port|IP|Action|Level[|Remark]")
+			unsupported := 0
+			for _, set := range firewall.Rule {
+				rule, err := firewallRuleFromSet(set)
+				if err != nil {
+					unsupported++
+					fmt.Fprintf(&buf, "# unsupported rule, %v\n", err)
+					continue
+				}
+				fmt.Fprintln(&buf, rule)
+			}
+			warning := ""
+			if unsupported > 0 {
+				warning = fmt.Sprintf("Warning: %d unsupported rules are exported as comments, 'ucloud firewall sync' with the file will remove them", unsupported)
+			}
+			if output == "" {
+				fmt.Fprint(out, buf.String())
+				//输出到标准输出时以注释的形式给出警告, 输出仍可作为规则文件使用
+				if warning != "" {
+					fmt.Fprintf(out, "# %s\n", warning)
+				}
+				return
+			}
+			err = ioutil.WriteFile(output, buf.Bytes(), base.LocalFileMode)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			fmt.Fprintf(out, "rules of firewall[%s] exported to %s\n", firewall.FWId, output)
+			if warning != "" {
+				fmt.Fprintln(out, warning)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&fwID, "fw-id", "", "Required. Resource ID of firewall to export")
+	flags.StringVar(&output, "output", "", "Optional. Path of file to write. Print to stdout by default")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+
+	flags.SetFlagValuesFunc("fw-id", func() []string {
+		return getFirewallIDNames(project, region)
+	})
+	flags.SetFlagValuesFunc("output", func() []string {
+		return base.GetFileList("")
+	})
+
+	cmd.MarkFlagRequired("fw-id")
+
+	return cmd
+}
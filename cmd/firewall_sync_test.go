@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffFirewallRulesTest struct {
+	current         []string
+	desired         []string
+	expectedAdded   []string
+	expectedRemoved []string
+}
+
+func (test *diffFirewallRulesTest) run(t *testing.T) {
+	current, err := parseFirewallRules(test.current)
+	if err != nil {
+		t.Fatalf("parseFirewallRules(%v), unexpected error: %v", test.current, err)
+	}
+	desired, err := parseFirewallRules(test.desired)
+	if err != nil {
+		t.Fatalf("parseFirewallRules(%v), unexpected error: %v", test.desired, err)
+	}
+	added, removed := diffFirewallRules(current, desired)
+	if got := firewallRuleStrings(added); !reflect.DeepEqual(got, test.expectedAdded) {
+		t.Errorf("diffFirewallRules(%v, %v), expected added %v, got %v", test.current, test.desired, test.expectedAdded, got)
+	}
+	if got := firewallRuleStrings(removed); !reflect.DeepEqual(got, test.expectedRemoved) {
+		t.Errorf("diffFirewallRules(%v, %v), expected removed %v, got %v", test.current, test.desired, test.expectedRemoved, got)
+	}
+}
+
+func TestDiffFirewallRules(t *testing.T) {
+	tests := []diffFirewallRulesTest{
+		{
+			current:         []string{"TCP|22|0.0.0.0/0|ACCEPT|HIGH"},
+			desired:         []string{"tcp|22|0.0.0.0/0|accept|high"},
+			expectedAdded:   []string{},
+			expectedRemoved: []string{},
+		},
+		{
+			current:         []string{"TCP|22|0.0.0.0/0|ACCEPT|HIGH", "TCP|80|0.0.0.0/0|ACCEPT|HIGH"},
+			desired:         []string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH", "TCP|443|0.0.0.0/0|ACCEPT|HIGH"},
+			expectedAdded:   []string{"TCP|443|0.0.0.0/0|ACCEPT|HIGH"},
+			expectedRemoved: []string{"TCP|22|0.0.0.0/0|ACCEPT|HIGH"},
+		},
+		{
+			current:         []string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH|web"},
+			desired:         []string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH|http"},
+			expectedAdded:   []string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH|http"},
+			expectedRemoved: []string{"TCP|80|0.0.0.0/0|ACCEPT|HIGH|web"},
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -0,0 +1,547 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	"github.com/ucloud/ucloud-sdk-go/services/ulb"
+	"github.com/ucloud/ucloud-sdk-go/services/unet"
+	"github.com/ucloud/ucloud-sdk-go/services/uphost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//NewCmdGroup ucloud group
+func NewCmdGroup(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "List and manipulate business groups",
+		Long:  "List and manipulate business groups(tag) across uhost, uphost, udisk, eip, ulb, udb and umem",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(NewCmdGroupList(out))
+	cmd.AddCommand(NewCmdGroupRename())
+	cmd.AddCommand(NewCmdGroupMove())
+	return cmd
+}
+
+//GroupRow 表格行
+type GroupRow struct {
+	Group  string
+	UHost  int
+	UPHost int
+	UDisk  int
+	EIP    int
+	ULB    int
+	UDB    int
+	UMem   int
+	Total  int
+}
+
+//GroupResourceRow 表格行
+type GroupResourceRow struct {
+	ResourceType string
+	ResourceID   string
+	Name         string
+	Group        string
+}
+
+//groupMovableTypes 支持修改业务组的资源类型
+var groupMovableTypes = []string{"uhost", "uphost", "eip", "ulb"}
+
+//NewCmdGroupList ucloud group list
+func NewCmdGroupList(out io.Writer) *cobra.Command {
+	var project, region, group string
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List business groups and count of resources in each group",
+		Long:    "List business groups and count of resources in each group. If group is assigned, list resources in the group",
+		Example: "ucloud group list; ucloud group list --group prod",
+		Run: func(c *cobra.Command, args []string) {
+			project = base.PickResourceID(project)
+			resources, warnings := getGroupResources(project, region)
+			printGroupWarnings(warnings)
+			if c.Flags().Changed("group") {
+				list := []GroupResourceRow{}
+				for _, rs := range resources {
+					if rs.Group == group {
+						list = append(list, rs)
+					}
+				}
+				base.PrintList(list, out)
+				return
+			}
+			rowMap := make(map[string]*GroupRow)
+			for _, rs := range resources {
+				row, ok := rowMap[rs.Group]
+				if !ok {
+					row = &GroupRow{Group: rs.Group}
+					rowMap[rs.Group] = row
+				}
+				switch rs.ResourceType {
+				case "uhost":
+					row.UHost++
+				case "uphost":
+					row.UPHost++
+				case "udisk":
+					row.UDisk++
+				case "eip":
+					row.EIP++
+				case "ulb":
+					row.ULB++
+				case "udb":
+					row.UDB++
+				case "umem":
+					row.UMem++
+				}
+				row.Total++
+			}
+			list := []GroupRow{}
+			for _, row := range rowMap {
+				list = append(list, *row)
+			}
+			sort.Slice(list, func(i, j int) bool {
+				return list[i].Group < list[j].Group
+			})
+			base.PrintList(list, out)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&group, "group", "", "Optional. Business group. If assigned, list resources in the group instead of count")
+	flags.SetFlagValuesFunc("group", func() []string {
+		return getGroupList(project, region)
+	})
+	return cmd
+}
+
+//NewCmdGroupRename ucloud group rename
+func NewCmdGroupRename() *cobra.Command {
+	var project, region, group, newName string
+	var yes bool
+	cmd := &cobra.Command{
+		Use:     "rename",
+		Short:   "Rename business group",
+		Long:    fmt.Sprintf("Rename business group by moving all resources in the group to a new group. Only %s support to change group", strings.Join(groupMovableTypes, ", ")),
+		Example: "ucloud group rename --group test --new-name staging",
+		Run: func(c *cobra.Command, args []string) {
+			project = base.PickResourceID(project)
+			resources, warnings := getGroupResources(project, region)
+			printGroupWarnings(warnings)
+			movable, unmovable := []GroupResourceRow{}, []string{}
+			for _, rs := range resources {
+				if rs.Group != group {
+					continue
+				}
+				if isGroupMovable(rs.ResourceType) {
+					movable = append(movable, rs)
+				} else {
+					unmovable = append(unmovable, fmt.Sprintf("%s[%s]", rs.ResourceType, rs.ResourceID))
+				}
+			}
+			if len(movable) == 0 {
+				base.Cxt.Printf("no resource can be moved in group %s\n", group)
+				return
+			}
+			if !yes {
+				sure, err := ux.Prompt(fmt.Sprintf("Are you sure you want to move %d resource(s) from group %s to %s?", len(movable), group, newName))
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			moveResourcesGroup(movable, newName, project, region)
+			if len(unmovable) > 0 {
+				base.Cxt.Printf("group of those resources can not be changed by cli, please change them in console: %s\n", strings.Join(unmovable, ", "))
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&group, "group", "", "Required. Business group to rename")
+	flags.StringVar(&newName, "new-name", "", "Required. New name of the business group")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation.")
+	flags.SetFlagValuesFunc("group", func() []string {
+		return getGroupList(project, region)
+	})
+	cmd.MarkFlagRequired("group")
+	cmd.MarkFlagRequired("new-name")
+	return cmd
+}
+
+//NewCmdGroupMove ucloud group move
+func NewCmdGroupMove() *cobra.Command {
+	var project, region, group string
+	var ids []string
+	cmd := &cobra.Command{
+		Use:     "move",
+		Short:   "Move resources to a business group",
+		Long:    fmt.Sprintf("Move resources to a business group. Accept resource types: %s", strings.Join(groupMovableTypes, ", ")),
+		Example: "ucloud group move --resource-id uhost-xxx,eip-xxx,ulb-xxx --to-group prod",
+		Run: func(c *cobra.Command, args []string) {
+			project = base.PickResourceID(project)
+			resources := []GroupResourceRow{}
+			for _, id := range ids {
+				id = base.PickResourceID(id)
+				rsType := groupResourceType(id)
+				if !isGroupMovable(rsType) {
+					base.Cxt.Printf("Error, resource %s is not supported, accept resource types: %s\n", id, strings.Join(groupMovableTypes, ", "))
+					return
+				}
+				resources = append(resources, GroupResourceRow{
+					ResourceType: rsType,
+					ResourceID:   id,
+				})
+			}
+			moveResourcesGroup(resources, group, project, region)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&ids, "resource-id", nil, "Required. Resource IDs of uhost, uphost, eip or ulb to move, multiple values separated by comma")
+	flags.StringVar(&group, "to-group", "", "Required. Business group to move resources to")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.SetFlagValuesFunc("to-group", func() []string {
+		return getGroupList(project, region)
+	})
+	cmd.MarkFlagRequired("resource-id")
+	cmd.MarkFlagRequired("to-group")
+	bindFromStdin(cmd, "resource-id")
+	return cmd
+}
+
+func isGroupMovable(rsType string) bool {
+	for _, t := range groupMovableTypes {
+		if t == rsType {
+			return true
+		}
+	}
+	return false
+}
+
+//groupResourceType 根据资源ID前缀判断资源类型
+func groupResourceType(id string) string {
+	prefixMap := map[string]string{
+		"uhost-": "uhost",
+		"phost-": "uphost",
+		"eip-":   "eip",
+		"ulb-":   "ulb",
+	}
+	for prefix, rsType := range prefixMap {
+		if strings.HasPrefix(id, prefix) {
+			return rsType
+		}
+	}
+	return ""
+}
+
+func moveResourcesGroup(resources []GroupResourceRow, group, project, region string) {
+	reqs := make([]request.Common, 0, len(resources))
+	for _, rs := range resources {
+		switch rs.ResourceType {
+		case "uhost":
+			req := base.BizClient.NewModifyUHostInstanceTagRequest()
+			req.UHostId = sdk.String(rs.ResourceID)
+			req.Tag = sdk.String(group)
+			req.ProjectId = sdk.String(project)
+			req.Region = sdk.String(region)
+			reqs = append(reqs, req)
+		case "uphost":
+			req := base.BizClient.NewModifyPHostInfoRequest()
+			req.PHostId = sdk.String(rs.ResourceID)
+			req.Tag = sdk.String(group)
+			req.ProjectId = sdk.String(project)
+			req.Region = sdk.String(region)
+			reqs = append(reqs, req)
+		case "eip":
+			req := base.BizClient.NewUpdateEIPAttributeRequest()
+			req.EIPId = sdk.String(rs.ResourceID)
+			req.Tag = sdk.String(group)
+			req.ProjectId = sdk.String(project)
+			req.Region = sdk.String(region)
+			reqs = append(reqs, req)
+		case "ulb":
+			req := base.BizClient.NewUpdateULBAttributeRequest()
+			req.ULBId = sdk.String(rs.ResourceID)
+			req.Tag = sdk.String(group)
+			req.ProjectId = sdk.String(project)
+			req.Region = sdk.String(region)
+			reqs = append(reqs, req)
+		}
+	}
+	coAction := newConcurrentAction(reqs, modifyGroup)
+	coAction.Do()
+}
+
+func modifyGroup(creq request.Common) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{}
+	var id, tag string
+	var err error
+	switch req := creq.(type) {
+	case *uhost.ModifyUHostInstanceTagRequest:
+		id, tag = *req.UHostId, *req.Tag
+		logs = append(logs, fmt.Sprintf("api:ModifyUHostInstanceTag, request:%v", base.ToQueryMap(req)))
+		_, err = base.BizClient.ModifyUHostInstanceTag(req)
+	case *uphost.ModifyPHostInfoRequest:
+		id, tag = *req.PHostId, *req.Tag
+		logs = append(logs, fmt.Sprintf("api:ModifyPHostInfo, request:%v", base.ToQueryMap(req)))
+		_, err = base.BizClient.ModifyPHostInfo(req)
+	case *unet.UpdateEIPAttributeRequest:
+		id, tag = *req.EIPId, *req.Tag
+		logs = append(logs, fmt.Sprintf("api:UpdateEIPAttribute, request:%v", base.ToQueryMap(req)))
+		_, err = base.BizClient.UpdateEIPAttribute(req)
+	case *ulb.UpdateULBAttributeRequest:
+		id, tag = *req.ULBId, *req.Tag
+		logs = append(logs, fmt.Sprintf("api:UpdateULBAttribute, request:%v", base.ToQueryMap(req)))
+		_, err = base.BizClient.UpdateULBAttribute(req)
+	}
+	if err != nil {
+		text := fmt.Sprintf("move %s to group %s failed: %s", id, tag, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("%s moved to group %s", id, tag)
+	block.Append(text)
+	return true, append(logs, text)
+}
+
+//groupResourceFetchers 各产品资源的获取方法
+var groupResourceFetchers = []struct {
+	product string
+	fetch   func(project, region string) ([]GroupResourceRow, error)
+}{
+	{"uhost", fetchGroupUHosts},
+	{"uphost", fetchGroupUPHosts},
+	{"udisk", fetchGroupUDisks},
+	{"eip", fetchGroupEIPs},
+	{"ulb", fetchGroupULBs},
+	{"udb", fetchGroupUDBs},
+	{"redis", fetchGroupRedis},
+	{"memcache", fetchGroupMemcache},
+}
+
+//getGroupResources 获取各产品的资源及其所属业务组. 某个产品获取失败(如地域或账号未开通该产品)时返回警告, 其他产品的资源照常统计
+func getGroupResources(project, region string) ([]GroupResourceRow, []string) {
+	list := []GroupResourceRow{}
+	warnings := []string{}
+	for _, f := range groupResourceFetchers {
+		rows, err := f.fetch(project, region)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("list %s failed, its resources are not included: %s", f.product, base.ParseError(err)))
+			continue
+		}
+		list = append(list, rows...)
+	}
+	return list, warnings
+}
+
+//printGroupWarnings 打印获取资源时的警告, json输出时只记录日志
+func printGroupWarnings(warnings []string) {
+	for _, w := range warnings {
+		base.LogInfo("Warning: " + w)
+		if !global.JSON {
+			base.Cxt.Printf("Warning: %s\n", w)
+		}
+	}
+}
+
+func fetchGroupUHosts(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	uhostReq := base.BizClient.NewDescribeUHostInstanceRequest()
+	uhostReq.ProjectId = sdk.String(project)
+	uhostReq.Region = sdk.String(region)
+	uhosts, err := getAllUHosts(uhostReq, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, ins := range uhosts {
+		list = append(list, GroupResourceRow{"uhost", ins.UHostId, ins.Name, ins.Tag})
+	}
+	return list, nil
+}
+
+func fetchGroupUPHosts(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	phostReq := base.BizClient.NewDescribePHostRequest()
+	phostReq.ProjectId = sdk.String(project)
+	phostReq.Region = sdk.String(region)
+	for offset, limit := 0, 50; ; offset += limit {
+		phostReq.Offset = sdk.Int(offset)
+		phostReq.Limit = sdk.Int(limit)
+		resp, err := base.BizClient.DescribePHost(phostReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range resp.PHostSet {
+			list = append(list, GroupResourceRow{"uphost", ins.PHostId, ins.Name, ins.Tag})
+		}
+		if offset+limit >= resp.TotalCount {
+			break
+		}
+	}
+	return list, nil
+}
+
+func fetchGroupUDisks(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	diskReq := base.BizClient.NewDescribeUDiskRequest()
+	diskReq.ProjectId = sdk.String(project)
+	diskReq.Region = sdk.String(region)
+	for offset, limit := 0, 50; ; offset += limit {
+		diskReq.Offset = sdk.Int(offset)
+		diskReq.Limit = sdk.Int(limit)
+		resp, err := base.BizClient.DescribeUDisk(diskReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range resp.DataSet {
+			list = append(list, GroupResourceRow{"udisk", ins.UDiskId, ins.Name, ins.Tag})
+		}
+		if offset+limit >= resp.TotalCount {
+			break
+		}
+	}
+	return list, nil
+}
+
+func fetchGroupEIPs(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	eips, err := fetchAllEip(project, region)
+	if err != nil {
+		return nil, err
+	}
+	for _, ins := range eips {
+		list = append(list, GroupResourceRow{"eip", ins.EIPId, ins.Name, ins.Tag})
+	}
+	return list, nil
+}
+
+func fetchGroupULBs(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	ulbs, err := getAllULB(project, region)
+	if err != nil {
+		return nil, err
+	}
+	for _, ins := range ulbs {
+		list = append(list, GroupResourceRow{"ulb", ins.ULBId, ins.Name, ins.Tag})
+	}
+	return list, nil
+}
+
+func fetchGroupUDBs(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	classTypes := []string{}
+	for _, dbType := range dbTypeList {
+		classType := dbTypeMap[dbType]
+		found := false
+		for _, t := range classTypes {
+			if t == classType {
+				found = true
+			}
+		}
+		if !found {
+			classTypes = append(classTypes, classType)
+		}
+	}
+	for _, classType := range classTypes {
+		udbs, err := getUDBList(nil, classType, project, region, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range udbs {
+			list = append(list, GroupResourceRow{"udb", ins.DBId, ins.Name, ins.Tag})
+		}
+	}
+	return list, nil
+}
+
+func fetchGroupRedis(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	redisReq := base.BizClient.NewDescribeURedisGroupRequest()
+	redisReq.ProjectId = sdk.String(project)
+	redisReq.Region = sdk.String(region)
+	for offset, limit := 0, 50; ; offset += limit {
+		redisReq.Offset = sdk.Int(offset)
+		redisReq.Limit = sdk.Int(limit)
+		resp, err := base.BizClient.DescribeURedisGroup(redisReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range resp.DataSet {
+			list = append(list, GroupResourceRow{"umem", ins.GroupId, ins.Name, ins.Tag})
+		}
+		if offset+limit >= resp.TotalCount {
+			break
+		}
+	}
+	return list, nil
+}
+
+func fetchGroupMemcache(project, region string) ([]GroupResourceRow, error) {
+	list := []GroupResourceRow{}
+	memcacheReq := base.BizClient.NewDescribeUMemcacheGroupRequest()
+	memcacheReq.ProjectId = sdk.String(project)
+	memcacheReq.Region = sdk.String(region)
+	for offset, limit := 0, 50; ; offset += limit {
+		memcacheReq.Offset = sdk.Int(offset)
+		memcacheReq.Limit = sdk.Int(limit)
+		resp, err := base.BizClient.DescribeUMemcacheGroup(memcacheReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range resp.DataSet {
+			list = append(list, GroupResourceRow{"umem", ins.GroupId, ins.Name, ins.Tag})
+		}
+		if offset+limit >= resp.TotalCount {
+			break
+		}
+	}
+	return list, nil
+}
+
+func getGroupList(project, region string) []string {
+	req := base.BizClient.NewDescribeUHostTagsRequest()
+	req.ProjectId = sdk.String(base.PickResourceID(project))
+	req.Region = sdk.String(region)
+	resp, err := base.BizClient.DescribeUHostTags(req)
+	if err != nil {
+		return nil
+	}
+	list := []string{}
+	for _, tag := range resp.TagSet {
+		list = append(list, tag.Tag)
+	}
+	return list
+}
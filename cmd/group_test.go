@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+)
+
+type groupResourceTypeTest struct {
+	id           string
+	expectedType string
+}
+
+func (test *groupResourceTypeTest) run(t *testing.T) {
+	rsType := groupResourceType(test.id)
+	if rsType != test.expectedType {
+		t.Errorf("groupResourceType(%q), expected %q, got %q", test.id, test.expectedType, rsType)
+	}
+}
+
+func TestGroupResourceType(t *testing.T) {
+	tests := []groupResourceTypeTest{
+		{id: "uhost-abc12", expectedType: "uhost"},
+		{id: "phost-abc12", expectedType: "uphost"},
+		{id: "eip-abc12", expectedType: "eip"},
+		{id: "ulb-abc12", expectedType: "ulb"},
+		{id: "bs-abc12", expectedType: ""},
+		{id: "udb-abc12", expectedType: ""},
+		{id: "uhost", expectedType: ""},
+		{id: "", expectedType: ""},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
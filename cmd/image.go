@@ -17,15 +17,22 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
 	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
 
 	"github.com/ucloud/ucloud-cli/base"
 	"github.com/ucloud/ucloud-cli/model/cli"
 	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
 )
 
 //NewCmdUImage ucloud uimage
@@ -38,8 +45,11 @@ func NewCmdUImage() *cobra.Command {
 	}
 	writer := base.Cxt.GetWriter()
 	cmd.AddCommand(NewCmdUImageList(writer))
+	cmd.AddCommand(NewCmdImageDescribe(writer))
 	cmd.AddCommand(NewCmdImageCopy(writer))
 	cmd.AddCommand(NewCmdUImageDelete())
+	cmd.AddCommand(NewCmdImageImport(writer))
+	cmd.AddCommand(NewCmdImagePrune(writer))
 	createImageCmd := NewCmdUhostCreateImage(writer)
 	createImageCmd.Use = "create"
 	cmd.AddCommand(createImageCmd)
@@ -61,32 +71,34 @@ type ImageRow struct {
 //NewCmdUImageList ucloud uimage list
 func NewCmdUImageList(out io.Writer) *cobra.Command {
 	req := base.BizClient.NewDescribeImageRequest()
+	var watch *watchOption
 	cmd := &cobra.Command{
 		Use:     "list",
 		Short:   "List image",
 		Long:    "List image",
 		Example: "ucloud image list --image-type Base",
 		Run: func(cmd *cobra.Command, args []string) {
-			resp, err := base.BizClient.DescribeImage(req)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
-			list := make([]ImageRow, 0)
-			for _, image := range resp.ImageSet {
-				row := ImageRow{}
-				row.ImageName = image.ImageName
-				row.ImageID = image.ImageId
-				row.ImageType = image.ImageType
-				row.BasicImage = image.OsName
-				row.ExtensibleFeature = strings.Join(image.Features, ",")
-				row.CreationTime = base.FormatDate(image.CreateTime)
-				row.State = image.State
-				if row.State == "Available" {
-					list = append(list, row)
+			printRows(watch, func() (interface{}, []string, error) {
+				resp, err := base.BizClient.DescribeImage(req)
+				if err != nil {
+					return nil, nil, err
 				}
-			}
-			base.PrintList(list, out)
+				list := make([]ImageRow, 0)
+				for _, image := range resp.ImageSet {
+					row := ImageRow{}
+					row.ImageName = image.ImageName
+					row.ImageID = image.ImageId
+					row.ImageType = image.ImageType
+					row.BasicImage = image.OsName
+					row.ExtensibleFeature = strings.Join(image.Features, ",")
+					row.CreationTime = base.FormatDate(image.CreateTime)
+					row.State = image.State
+					if row.State == "Available" {
+						list = append(list, row)
+					}
+				}
+				return list, nil, nil
+			}, out)
 		},
 	}
 	req.ProjectId = cmd.Flags().String("project-id", base.ConfigIns.ProjectID, "Optional. Assign project-id")
@@ -97,13 +109,109 @@ func NewCmdUImageList(out io.Writer) *cobra.Command {
 	req.ImageId = cmd.Flags().String("image-id", "", "Optional. Resource ID of image")
 	req.Offset = cmd.Flags().Int("offset", 0, "Optional. Offset default 0")
 	req.Limit = cmd.Flags().Int("limit", 500, "Optional. Max count")
+	watch = bindWatch(cmd.Flags())
 	cmd.Flags().SetFlagValues("image-type", "Base", "Business", "Custom")
 	return cmd
 }
 
-// func NewCmdImageImport() *cobra.Command {
-// 	req := BizClient.NewImportCustomImageRequest()
-// }
+//NewCmdImageImport ucloud image import
+func NewCmdImageImport(out io.Writer) *cobra.Command {
+	var file, bucket, key string
+	var deleteObject, async bool
+	req := base.BizClient.NewImportCustomImageRequest()
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import custom image from local file",
+		Long: `Import custom image from local file. The file is uploaded to a UFile bucket first, and then imported as a custom image.
+If the uploading is interrupted, run the same command again to resume it`,
+		Example: "ucloud image import --file disk.qcow2 --bucket images --os-type Linux --os-name CentOS-7 --format qcow2 --image-name centos7",
+		Run: func(c *cobra.Command, args []string) {
+			if deleteObject && async {
+				base.Cxt.Println("Error, delete-file can not be used together with async, the file can only be deleted after the image is available")
+				return
+			}
+			if key == "" {
+				key = filepath.Base(file)
+			}
+			if *req.ImageName == "" {
+				req.ImageName = sdk.String(strings.TrimSuffix(key, filepath.Ext(key)))
+			}
+			obj, err := newUFileObject(bucket, key, *req.ProjectId)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			refresh := ux.NewRefresh()
+			err = obj.uploadFile(file, func(done, total int64) {
+				percent := int64(100)
+				if total > 0 {
+					percent = done * 100 / total
+				}
+				refresh.Do(fmt.Sprintf("uploading %s to %s/%s...%d%% (%dMB/%dMB)", file, bucket, key, percent, done>>20, total>>20))
+			})
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			req.UFileUrl = sdk.String(obj.signedURL(time.Now().Add(24 * time.Hour)))
+			resp, err := base.BizClient.ImportCustomImage(req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			text := fmt.Sprintf("image[%s] is importing", resp.ImageId)
+			if async {
+				fmt.Fprintln(out, text)
+				return
+			}
+			poller := base.NewPoller(describeImageByID, out)
+			poller.Poll(resp.ImageId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.IMAGE_AVAILABLE, status.IMAGE_UNAVAILABLE})
+			if !deleteObject {
+				return
+			}
+			image, err := describeImageByID(resp.ImageId, *req.ProjectId, *req.Region, *req.Zone)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if image == nil || image.(*uhost.UHostImageSet).State != status.IMAGE_AVAILABLE {
+				fmt.Fprintf(out, "image[%s] is not available, %s/%s is kept\n", resp.ImageId, bucket, key)
+				return
+			}
+			err = obj.delete()
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			fmt.Fprintf(out, "%s/%s deleted\n", bucket, key)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&file, "file", "", "Required. Path of the local image file")
+	flags.StringVar(&bucket, "bucket", "", "Required. Name of the UFile bucket to upload the image file to")
+	flags.StringVar(&key, "key", "", "Optional. Key of the image file in the bucket. Name of the local file by default")
+	req.ImageName = flags.String("image-name", "", "Optional. Name of the image. Key without extension by default")
+	req.OsType = flags.String("os-type", "", "Required. Type of operating system. Accept values: Linux, Windows")
+	req.OsName = flags.String("os-name", "", "Required. Name of operating system, such as CentOS-7, Ubuntu-18.04")
+	req.Format = flags.String("format", "", "Required. Format of the image file. Accept values: raw, vhd, vmdk, qcow2")
+	req.ImageDescription = flags.String("image-desc", "", "Optional. Description of the image")
+	flags.BoolVar(&deleteObject, "delete-file", false, "Optional. Delete the image file in the bucket after the image is available")
+	flags.BoolVar(&async, "async", false, "Optional. Do not wait for the long-running operation to finish.")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	req.Auth = sdk.Bool(true)
+	flags.SetFlagValues("os-type", "Linux", "Windows")
+	flags.SetFlagValues("format", "raw", "vhd", "vmdk", "qcow2")
+	flags.SetFlagValues("delete-file", "true", "false")
+	cmd.MarkFlagRequired("file")
+	cmd.MarkFlagRequired("bucket")
+	cmd.MarkFlagRequired("os-type")
+	cmd.MarkFlagRequired("os-name")
+	cmd.MarkFlagRequired("format")
+	return cmd
+}
 
 //NewCmdUImageDelete ucloud image delete
 func NewCmdUImageDelete() *cobra.Command {
@@ -139,34 +247,317 @@ func NewCmdUImageDelete() *cobra.Command {
 	return cmd
 }
 
+//ImageUsageRow 使用镜像的主机
+type ImageUsageRow struct {
+	ResourceID string
+	Name       string
+	Zone       string
+	State      string
+	Usage      string
+}
+
+//ImageDescribe image describe的json输出
+type ImageDescribe struct {
+	Attributes []base.DescribeTableRow
+	UsedBy     []ImageUsageRow
+}
+
+//NewCmdImageDescribe ucloud image describe
+func NewCmdImageDescribe(out io.Writer) *cobra.Command {
+	var imageID string
+	var project, region, zone string
+	cmd := &cobra.Command{
+		Use:     "describe",
+		Short:   "Display details of an image and the uhost instances using it",
+		Long:    "Display details of an image and the uhost instances using it. An image is safe to delete if no uhost instance uses it",
+		Example: "ucloud image describe --image-id uimage-xxx",
+		Run: func(c *cobra.Command, args []string) {
+			imageID = base.PickResourceID(imageID)
+			inst, err := describeImageByID(imageID, project, region, zone)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if inst == nil {
+				base.Cxt.Printf("Error, image[%s] does not exist\n", imageID)
+				return
+			}
+			image := inst.(*uhost.UHostImageSet)
+			attrs := []base.DescribeTableRow{
+				base.DescribeTableRow{Attribute: "ImageID", Content: image.ImageId},
+				base.DescribeTableRow{Attribute: "ImageName", Content: image.ImageName},
+				base.DescribeTableRow{Attribute: "ImageType", Content: image.ImageType},
+				base.DescribeTableRow{Attribute: "State", Content: image.State},
+				base.DescribeTableRow{Attribute: "Zone", Content: image.Zone},
+				base.DescribeTableRow{Attribute: "OsType", Content: image.OsType},
+				base.DescribeTableRow{Attribute: "OsName", Content: image.OsName},
+				base.DescribeTableRow{Attribute: "ImageSize", Content: fmt.Sprintf("%dGB", image.ImageSize)},
+				base.DescribeTableRow{Attribute: "Features", Content: strings.Join(image.Features, ",")},
+				base.DescribeTableRow{Attribute: "MinimalCPU", Content: image.MinimalCPU},
+				base.DescribeTableRow{Attribute: "FuncType", Content: image.FuncType},
+				base.DescribeTableRow{Attribute: "IntegratedSoftware", Content: image.IntegratedSoftware},
+				base.DescribeTableRow{Attribute: "Vendor", Content: image.Vendor},
+				base.DescribeTableRow{Attribute: "Links", Content: image.Links},
+				base.DescribeTableRow{Attribute: "Description", Content: image.ImageDescription},
+				base.DescribeTableRow{Attribute: "CreationTime", Content: base.FormatDate(image.CreateTime)},
+			}
+			usedBy, err := getImageUsage(project, region, "")
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			rows := []ImageUsageRow{}
+			for _, host := range usedBy[image.ImageId] {
+				row := ImageUsageRow{
+					ResourceID: host.UHostId,
+					Name:       host.Name,
+					Zone:       host.Zone,
+					State:      host.State,
+					Usage:      "Image",
+				}
+				if host.ImageId != image.ImageId {
+					row.Usage = "BasicImage"
+				}
+				rows = append(rows, row)
+			}
+			if global.JSON {
+				base.PrintJSON(ImageDescribe{Attributes: attrs, UsedBy: rows}, out)
+				return
+			}
+			fmt.Fprintln(out, "Attributes:")
+			base.PrintTableS(attrs)
+			if len(rows) == 0 {
+				fmt.Fprintf(out, "\nimage[%s] is not used by any uhost in region %s\n", image.ImageId, region)
+				return
+			}
+			fmt.Fprintln(out, "\nUsed by:")
+			base.PrintTableS(rows)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&imageID, "image-id", "", "Required. Resource ID of the image to describe")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", "", "Optional. Assign availability zone")
+	flags.SetFlagValuesFunc("image-id", func() []string {
+		return getImageList([]string{status.IMAGE_AVAILABLE, status.IMAGE_MAKING, status.IMAGE_UNAVAILABLE}, cli.IMAGE_ALL, project, region, zone)
+	})
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	cmd.MarkFlagRequired("image-id")
+	return cmd
+}
+
+//getImageUsage 地域内使用各镜像的主机, 按镜像ID分组. 主机的镜像和基础镜像都计入
+func getImageUsage(project, region, zone string) (map[string][]uhost.UHostInstanceSet, error) {
+	req := base.BizClient.NewDescribeUHostInstanceRequest()
+	req.ProjectId = sdk.String(project)
+	req.Region = sdk.String(region)
+	req.Zone = sdk.String(zone)
+	uhosts, err := getAllUHosts(req, true, false)
+	if err != nil {
+		return nil, err
+	}
+	usedBy := make(map[string][]uhost.UHostInstanceSet)
+	for _, host := range uhosts {
+		usedBy[host.ImageId] = append(usedBy[host.ImageId], host)
+		if host.BasicImageId != "" && host.BasicImageId != host.ImageId {
+			usedBy[host.BasicImageId] = append(usedBy[host.BasicImageId], host)
+		}
+	}
+	return usedBy, nil
+}
+
+//ImagePruneRow image prune表格行
+type ImagePruneRow struct {
+	ImageName    string
+	ImageID      string
+	CreationTime string
+	Action       string
+}
+
+//NewCmdImagePrune ucloud image prune
+func NewCmdImagePrune(out io.Writer) *cobra.Command {
+	var olderThan, namePrefix string
+	var keepLast int
+	var dryRun, yes bool
+	req := base.BizClient.NewDescribeImageRequest()
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old custom images",
+		Long: `Delete custom images which are older than older-than, except the latest keep-last ones and those used by uhost instances.
+Only images whose name starts with name-prefix are considered if it is assigned`,
+		Example: "ucloud image prune --older-than 90d --keep-last 5 --name-prefix app- --dry-run",
+		Run: func(c *cobra.Command, args []string) {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if keepLast < 0 {
+				base.Cxt.Printf("Error, keep-last should not be negative, got %d\n", keepLast)
+				return
+			}
+			imageSet, err := fetchImagesPageOff(req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			images := []uhost.UHostImageSet{}
+			for _, image := range imageSet {
+				if strings.HasPrefix(image.ImageName, namePrefix) {
+					images = append(images, image)
+				}
+			}
+			sort.Slice(images, func(i, j int) bool {
+				return images[i].CreateTime > images[j].CreateTime
+			})
+
+			//自制镜像在整个地域可用, 需检查所有可用区的主机
+			usedBy, err := getImageUsage(*req.ProjectId, *req.Region, "")
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+
+			deadline := time.Now().Add(-age).Unix()
+			rows := []ImagePruneRow{}
+			deleteIDs := []string{}
+			for idx, image := range images {
+				row := ImagePruneRow{
+					ImageName:    image.ImageName,
+					ImageID:      image.ImageId,
+					CreationTime: base.FormatDate(image.CreateTime),
+				}
+				if idx < keepLast {
+					row.Action = "keep, latest"
+				} else if int64(image.CreateTime) > deadline {
+					row.Action = "keep, newer than " + olderThan
+				} else if hosts, ok := usedBy[image.ImageId]; ok {
+					ids := []string{}
+					for _, host := range hosts {
+						ids = append(ids, host.UHostId)
+					}
+					row.Action = fmt.Sprintf("keep, used by uhost[%s]", strings.Join(ids, ","))
+				} else if image.State != status.IMAGE_AVAILABLE && image.State != status.IMAGE_UNAVAILABLE {
+					row.Action = "keep, " + image.State
+				} else {
+					row.Action = "delete"
+					deleteIDs = append(deleteIDs, image.ImageId)
+				}
+				rows = append(rows, row)
+			}
+			base.PrintList(rows, out)
+			if dryRun || len(deleteIDs) == 0 {
+				return
+			}
+			if !yes {
+				sure, err := ux.Prompt(fmt.Sprintf("Are you sure you want to delete %d image(s)?", len(deleteIDs)))
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			deleteReq := base.BizClient.NewTerminateCustomImageRequest()
+			deleteReq.ProjectId = req.ProjectId
+			deleteReq.Region = req.Region
+			deleteReq.Zone = req.Zone
+			for _, id := range deleteIDs {
+				deleteReq.ImageId = sdk.String(id)
+				_, err := base.BizClient.TerminateCustomImage(deleteReq)
+				if err != nil {
+					base.HandleError(err)
+					continue
+				}
+				fmt.Fprintf(out, "image[%s] deleted\n", id)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&olderThan, "older-than", "90d", "Optional. Only images created before this duration are deleted, such as 90d, 720h")
+	flags.IntVar(&keepLast, "keep-last", 5, "Optional. The number of latest images to keep regardless of age")
+	flags.StringVar(&namePrefix, "name-prefix", "", "Optional. Only images whose name starts with the prefix are considered")
+	flags.BoolVar(&dryRun, "dry-run", false, "Optional. Only list the images to delete")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation.")
+	req.ImageType = sdk.String(cli.IAMGE_CUSTOM)
+	flags.SetFlagValues("dry-run", "true", "false")
+	return cmd
+}
+
+//fetchImagesPageOff 分页获取全部镜像
+func fetchImagesPageOff(req *uhost.DescribeImageRequest) ([]uhost.UHostImageSet, error) {
+	_req := *req
+	result := make([]uhost.UHostImageSet, 0)
+	for limit, offset := 100, 0; ; offset += limit {
+		_req.Offset = sdk.Int(offset)
+		_req.Limit = sdk.Int(limit)
+		resp, err := base.BizClient.DescribeImage(&_req)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, resp.ImageSet...)
+		if offset+limit >= resp.TotalCount || len(resp.ImageSet) == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
+//parseAge 解析时长, 除time.ParseDuration支持的格式外还支持天, 如90d
+func parseAge(age string) (time.Duration, error) {
+	if strings.HasSuffix(age, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(age, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid duration %q", age)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", age)
+	}
+	return d, nil
+}
+
 //NewCmdImageCopy ucloud image copy
 func NewCmdImageCopy(out io.Writer) *cobra.Command {
-	var imageIDs *[]string
+	var imageIDs, targetRegions *[]string
 	var async *bool
 	req := base.BizClient.NewCopyCustomImageRequest()
 	cmd := &cobra.Command{
-		Use:   "copy",
-		Short: "Copy custom images",
-		Long:  "Copy custom images",
+		Use:     "copy",
+		Short:   "Copy custom images",
+		Long:    "Copy custom images. Images are copied to all the target regions concurrently",
+		Example: "ucloud image copy --source-image-id uimage-xxx --target-region cn-sh2,hk",
 		Run: func(c *cobra.Command, args []string) {
 			*req.ProjectId = base.PickResourceID(*req.ProjectId)
 			*req.TargetProjectId = base.PickResourceID(*req.TargetProjectId)
+			if len(*targetRegions) == 0 {
+				base.Cxt.Println("Error, target-region is required")
+				return
+			}
+			reqs := []request.Common{}
 			for _, id := range *imageIDs {
-				id = base.PickResourceID(id)
-				req.SourceImageId = &id
-				resp, err := base.BizClient.CopyCustomImage(req)
-				if err != nil {
-					base.HandleError(err)
-					return
-				}
-				text := fmt.Sprintf("image[%s] is coping", resp.TargetImageId)
-				if *async {
-					fmt.Fprintln(out, text)
-				} else {
-					poller := base.NewPoller(describeImageByID, out)
-					poller.Poll(resp.TargetImageId, *req.TargetProjectId, *req.TargetRegion, "", text, []string{status.IMAGE_AVAILABLE, status.IMAGE_UNAVAILABLE})
+				for _, region := range *targetRegions {
+					_req := *req
+					_req.SourceImageId = sdk.String(base.PickResourceID(id))
+					_req.TargetRegion = sdk.String(region)
+					reqs = append(reqs, &_req)
 				}
 			}
+			coAction := newConcurrentAction(reqs, func(creq request.Common) (bool, []string) {
+				return copyImage(creq.(*uhost.CopyCustomImageRequest), *async)
+			})
+			coAction.Do()
 		},
 	}
 	flags := cmd.Flags()
@@ -175,7 +566,7 @@ func NewCmdImageCopy(out io.Writer) *cobra.Command {
 	req.ProjectId = cmd.Flags().String("project-id", base.ConfigIns.ProjectID, "Optional. Assign project-id")
 	req.Region = cmd.Flags().String("region", base.ConfigIns.Region, "Optional. Assign region")
 	req.Zone = cmd.Flags().String("zone", base.ConfigIns.Zone, "Optional. Assign availability zone")
-	req.TargetRegion = flags.String("target-region", base.ConfigIns.Region, "Optional. Target region. See 'ucloud region'")
+	targetRegions = flags.StringSlice("target-region", []string{base.ConfigIns.Region}, "Optional. Target regions, separated by comma. See 'ucloud region'")
 	req.TargetProjectId = flags.String("target-project", base.ConfigIns.ProjectID, "Optional. Target Project ID. See 'ucloud project list'")
 	req.TargetImageName = flags.String("target-image-name", "", "Optional. Name of target image")
 	req.TargetImageDescription = flags.String("target-image-desc", "", "Optional. Description of target image")
@@ -197,6 +588,39 @@ func NewCmdImageCopy(out io.Writer) *cobra.Command {
 	return cmd
 }
 
+//copyImage 可并发调用, 复制镜像到目标地域并等待目标镜像可用
+func copyImage(req *uhost.CopyCustomImageRequest, async bool) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:CopyCustomImage, request:%v", base.ToQueryMap(req))}
+	resp, err := base.BizClient.CopyCustomImage(req)
+	if err != nil {
+		text := fmt.Sprintf("copy image[%s] to %s failed: %s", *req.SourceImageId, *req.TargetRegion, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("image[%s] is copying to %s as image[%s]", *req.SourceImageId, *req.TargetRegion, resp.TargetImageId)
+	logs = append(logs, text)
+	if async {
+		block.Append(text)
+		return true, logs
+	}
+	poller := base.NewSpoller(func(imageID string) (interface{}, error) {
+		return describeImageByID(imageID, *req.TargetProjectId, *req.TargetRegion, "")
+	}, base.Cxt.GetWriter())
+	ret := poller.Sspoll(resp.TargetImageId, text, []string{status.IMAGE_AVAILABLE, status.IMAGE_UNAVAILABLE}, block)
+	if ret.Timeout {
+		text := fmt.Sprintf("wait image[%s] in %s timeout", resp.TargetImageId, *req.TargetRegion)
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if ret.Err != nil {
+		block.Append(ret.Err.Error())
+		return false, append(logs, ret.Err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("image[%s] copied to %s", resp.TargetImageId, *req.TargetRegion))
+}
+
 func getImageList(states []string, imageType, project, region, zone string) []string {
 	req := base.BizClient.NewDescribeImageRequest()
 	req.ProjectId = &project
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+type parseAgeTest struct {
+	age              string
+	expectedDuration time.Duration
+	expectedErr      bool
+}
+
+func (test *parseAgeTest) run(t *testing.T) {
+	d, err := parseAge(test.age)
+	if test.expectedErr {
+		if err == nil {
+			t.Errorf("parseAge(%q), expected error, got %v", test.age, d)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("parseAge(%q), unexpected error: %v", test.age, err)
+	}
+	if d != test.expectedDuration {
+		t.Errorf("parseAge(%q), expected %v, got %v", test.age, test.expectedDuration, d)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []parseAgeTest{
+		{age: "90d", expectedDuration: 90 * 24 * time.Hour},
+		{age: "0d", expectedDuration: 0},
+		{age: "36h", expectedDuration: 36 * time.Hour},
+		{age: "1h30m", expectedDuration: 90 * time.Minute},
+		{age: "d", expectedErr: true},
+		{age: "-1d", expectedErr: true},
+		{age: "1.5d", expectedErr: true},
+		{age: "-2h", expectedErr: true},
+		{age: "90", expectedErr: true},
+		{age: "", expectedErr: true},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -198,6 +198,7 @@ type UDBMysqlRow struct {
 //NewCmdUDBList ucloud udb list
 func NewCmdUDBList(out io.Writer) *cobra.Command {
 	req := base.BizClient.NewDescribeUDBInstanceRequest()
+	var watch *watchOption
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List MySQL instances",
@@ -206,45 +207,46 @@ func NewCmdUDBList(out io.Writer) *cobra.Command {
 			if *req.DBId != "" {
 				*req.DBId = base.PickResourceID(*req.DBId)
 			}
-			resp, err := base.BizClient.DescribeUDBInstance(req)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
-			list := []UDBMysqlRow{}
-			for _, ins := range resp.DataSet {
-				row := UDBMysqlRow{}
-				row.Name = ins.Name
-				row.Zone = ins.Zone
-				row.Role = ins.Role
-				row.ResourceID = ins.DBId
-				row.Group = ins.Tag
-				row.VPC = ins.VPCId
-				row.Subnet = ins.SubnetId
-				row.IP = ins.VirtualIP
-				row.Mode = ins.InstanceMode
-				row.DiskType = ins.InstanceType
-				row.Status = ins.State
-				row.Config = fmt.Sprintf("%s|%dG|%dG", ins.DBTypeId, ins.MemoryLimit/1000, ins.DiskSpace)
-				list = append(list, row)
-				for _, slave := range ins.DataSet {
+			printRows(watch, func() (interface{}, []string, error) {
+				resp, err := base.BizClient.DescribeUDBInstance(req)
+				if err != nil {
+					return nil, nil, err
+				}
+				list := []UDBMysqlRow{}
+				for _, ins := range resp.DataSet {
 					row := UDBMysqlRow{}
-					row.Name = slave.Name
-					row.Zone = slave.Zone
-					row.Role = fmt.Sprintf("\u2b91 %s", slave.Role)
-					row.ResourceID = slave.DBId
-					row.Group = slave.Tag
-					row.VPC = slave.VPCId
-					row.Subnet = slave.SubnetId
-					row.IP = slave.VirtualIP
-					row.Mode = slave.InstanceMode
-					row.DiskType = slave.InstanceType
-					row.Config = fmt.Sprintf("%s|%dG|%dG", slave.DBTypeId, slave.MemoryLimit/1000, slave.DiskSpace)
-					row.Status = slave.State
+					row.Name = ins.Name
+					row.Zone = ins.Zone
+					row.Role = ins.Role
+					row.ResourceID = ins.DBId
+					row.Group = ins.Tag
+					row.VPC = ins.VPCId
+					row.Subnet = ins.SubnetId
+					row.IP = ins.VirtualIP
+					row.Mode = ins.InstanceMode
+					row.DiskType = ins.InstanceType
+					row.Status = ins.State
+					row.Config = fmt.Sprintf("%s|%dG|%dG", ins.DBTypeId, ins.MemoryLimit/1000, ins.DiskSpace)
 					list = append(list, row)
+					for _, slave := range ins.DataSet {
+						row := UDBMysqlRow{}
+						row.Name = slave.Name
+						row.Zone = slave.Zone
+						row.Role = fmt.Sprintf("\u2b91 %s", slave.Role)
+						row.ResourceID = slave.DBId
+						row.Group = slave.Tag
+						row.VPC = slave.VPCId
+						row.Subnet = slave.SubnetId
+						row.IP = slave.VirtualIP
+						row.Mode = slave.InstanceMode
+						row.DiskType = slave.InstanceType
+						row.Config = fmt.Sprintf("%s|%dG|%dG", slave.DBTypeId, slave.MemoryLimit/1000, slave.DiskSpace)
+						row.Status = slave.State
+						list = append(list, row)
+					}
 				}
-			}
-			base.PrintList(list, out)
+				return list, nil, nil
+			}, out)
 		},
 	}
 	flags := cmd.Flags()
@@ -258,6 +260,7 @@ func NewCmdUDBList(out io.Writer) *cobra.Command {
 	bindOffset(req, flags)
 	req.IncludeSlaves = flags.Bool("include-slaves", false, "Optional. When specifying the udb-id, whether to display its slaves together. Accept values:true, false")
 	req.ClassType = sdk.String("sql")
+	watch = bindWatch(flags)
 
 	flags.SetFlagValues("include-slaves", "true", "false")
 	flags.SetFlagValuesFunc("udb-id", func() []string {
@@ -196,33 +196,34 @@ func getPortStr(list []ppathx.UGAATask) string {
 //NewCmdUGADescribe ucloud uga describe
 func NewCmdUGADescribe(out io.Writer) *cobra.Command {
 	req := base.BizClient.NewDescribeUGAInstanceRequest()
+	var watch *watchOption
 	cmd := &cobra.Command{
 		Use:   "describe",
 		Short: "Display detail informations about uga instances",
 		Long:  "Display detail informations about uga instances",
 		Run: func(c *cobra.Command, args []string) {
 			*req.UGAId = base.PickResourceID(*req.UGAId)
-			resp, err := base.BizClient.DescribeUGAInstance(req)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
-			if len(resp.UGAList) != 1 {
-				base.HandleError(fmt.Errorf("uga[%s] may not exist", *req.UGAId))
-				return
-			}
+			printRows(watch, func() (interface{}, []string, error) {
+				resp, err := base.BizClient.DescribeUGAInstance(req)
+				if err != nil {
+					return nil, nil, err
+				}
+				if len(resp.UGAList) != 1 {
+					return nil, nil, fmt.Errorf("uga[%s] may not exist", *req.UGAId)
+				}
 
-			ins := resp.UGAList[0]
-			list := []base.DescribeTableRow{
-				base.DescribeTableRow{"ResourceID", ins.UGAId},
-				base.DescribeTableRow{"UGAName", ins.UGAName},
-				base.DescribeTableRow{"Origin", fmt.Sprintf("%s%s", ins.Domain, strings.Join(ins.IPList, ","))},
-				base.DescribeTableRow{"CName", ins.CName},
-				base.DescribeTableRow{"AcceleratedPath", getUpathStr(ins.UPathSet)},
-				base.DescribeTableRow{"OutIP", getOutIPStr(ins.OutPublicIpList)},
-				base.DescribeTableRow{"Port", getPortStr(ins.TaskSet)},
-			}
-			base.PrintList(list, out)
+				ins := resp.UGAList[0]
+				list := []base.DescribeTableRow{
+					base.DescribeTableRow{"ResourceID", ins.UGAId},
+					base.DescribeTableRow{"UGAName", ins.UGAName},
+					base.DescribeTableRow{"Origin", fmt.Sprintf("%s%s", ins.Domain, strings.Join(ins.IPList, ","))},
+					base.DescribeTableRow{"CName", ins.CName},
+					base.DescribeTableRow{"AcceleratedPath", getUpathStr(ins.UPathSet)},
+					base.DescribeTableRow{"OutIP", getOutIPStr(ins.OutPublicIpList)},
+					base.DescribeTableRow{"Port", getPortStr(ins.TaskSet)},
+				}
+				return list, nil, nil
+			}, out)
 		},
 	}
 
@@ -231,6 +232,7 @@ func NewCmdUGADescribe(out io.Writer) *cobra.Command {
 
 	req.UGAId = flags.String("uga-id", "", "Required. Resource ID of uga instance")
 	bindProjectID(req, flags)
+	watch = bindWatch(flags)
 
 	cmd.MarkFlagRequired("uga-id")
 	flags.SetFlagValuesFunc("uga-id", func() []string {
@@ -184,6 +184,10 @@ func Execute() {
 	cmd.AddCommand(NewCmdMemcache())
 	cmd.AddCommand(NewCmdExt())
 	cmd.AddCommand(NewCmdUFlink())
+	cmd.AddCommand(NewCmdExport())
+	cmd.AddCommand(NewCmdBatch())
+	cmd.AddCommand(NewCmdAPI())
+	cmd.AddCommand(NewCmdGroup(out))
 	for _, c := range cmd.Commands() {
 		if c.Name() != "init" && c.Name() != "gendoc" && c.Name() != "config" {
 			c.PersistentFlags().StringVar(&global.PublicKey, "public-key", global.PublicKey, "Set public key to override the public key in local config file")
@@ -0,0 +1,298 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+
+	"github.com/ucloud/ucloud-cli/base"
+)
+
+//ufileObject UFile中的文件, host为bucket的源站域名, 例如 bucket.cn-bj.ufileos.com
+type ufileObject struct {
+	bucket string
+	host   string
+	key    string
+	client *http.Client
+}
+
+//ufileMultipartState 分片上传的进度, 保存在本地用于断点续传
+type ufileMultipartState struct {
+	Bucket   string   `json:"bucket"`
+	Key      string   `json:"key"`
+	FileSize int64    `json:"file_size"`
+	ModTime  int64    `json:"mod_time"`
+	UploadID string   `json:"upload_id"`
+	BlkSize  int64    `json:"blk_size"`
+	ETags    []string `json:"etags"`
+}
+
+func newUFileObject(bucket, key, project string) (*ufileObject, error) {
+	req := base.BizClient.NewDescribeBucketRequest()
+	req.ProjectId = sdk.String(project)
+	req.BucketName = sdk.String(bucket)
+	resp, err := base.BizClient.DescribeBucket(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.DataSet) == 0 {
+		return nil, fmt.Errorf("bucket %s does not exist", bucket)
+	}
+	if len(resp.DataSet[0].Domain.Src) == 0 {
+		return nil, fmt.Errorf("source domain of bucket %s not found", bucket)
+	}
+	return &ufileObject{
+		bucket: bucket,
+		host:   resp.DataSet[0].Domain.Src[0],
+		key:    key,
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+//sign 签名, 参见 https://docs.ucloud.cn/ufile/api/authorization
+func (o *ufileObject) sign(method, contentType, date string) string {
+	text := strings.Join([]string{method, "", contentType, date, ""}, "\n") + "/" + o.bucket + "/" + o.key
+	mac := hmac.New(sha1.New, []byte(base.AuthCredential.PrivateKey))
+	mac.Write([]byte(text))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (o *ufileObject) url(query string) string {
+	u := url.URL{
+		Scheme:   "https",
+		Host:     o.host,
+		Path:     "/" + o.key,
+		RawQuery: query,
+	}
+	return u.String()
+}
+
+//signedURL 带签名的下载地址, 在expires之前有效
+func (o *ufileObject) signedURL(expires time.Time) string {
+	expiresStr := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{}
+	query.Set("UCloudPublicKey", base.AuthCredential.PublicKey)
+	query.Set("Signature", o.sign("GET", "", expiresStr))
+	query.Set("Expires", expiresStr)
+	return o.url(query.Encode())
+}
+
+func (o *ufileObject) do(method, query, contentType string, body []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, o.url(query), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Date", date)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("UCloud %s:%s", base.AuthCredential.PublicKey, o.sign(method, contentType, date)))
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		ret := struct {
+			RetCode int
+			ErrMsg  string
+		}{}
+		json.Unmarshal(content, &ret)
+		return nil, nil, &ufileError{
+			method:     method,
+			key:        o.key,
+			status:     resp.Status,
+			statusCode: resp.StatusCode,
+			retCode:    ret.RetCode,
+			errMsg:     ret.ErrMsg,
+		}
+	}
+	return resp, content, nil
+}
+
+//ufileError UFile接口返回的错误
+type ufileError struct {
+	method     string
+	key        string
+	status     string
+	statusCode int
+	retCode    int
+	errMsg     string
+}
+
+func (e *ufileError) Error() string {
+	if e.errMsg != "" {
+		return fmt.Sprintf("%s %s failed, RetCode:%d, ErrMsg:%s", e.method, e.key, e.retCode, e.errMsg)
+	}
+	return fmt.Sprintf("%s %s failed, status:%s", e.method, e.key, e.status)
+}
+
+//isUnknownUpload 分片上传已过期或已被取消
+func isUnknownUpload(err error) bool {
+	e, ok := err.(*ufileError)
+	if !ok {
+		return false
+	}
+	return e.statusCode == http.StatusNotFound || strings.Contains(strings.ToLower(e.errMsg), "uploadid")
+}
+
+func (o *ufileObject) delete() error {
+	_, _, err := o.do("DELETE", "", "", nil)
+	return err
+}
+
+func (o *ufileObject) initMultipart() (string, int64, error) {
+	_, content, err := o.do("POST", "uploads", "application/octet-stream", nil)
+	if err != nil {
+		return "", 0, err
+	}
+	ret := struct {
+		UploadId string
+		BlkSize  int64
+	}{}
+	err = json.Unmarshal(content, &ret)
+	if err != nil {
+		return "", 0, err
+	}
+	if ret.UploadId == "" || ret.BlkSize <= 0 {
+		return "", 0, fmt.Errorf("init multipart upload of %s failed: %s", o.key, content)
+	}
+	return ret.UploadId, ret.BlkSize, nil
+}
+
+func (o *ufileObject) uploadPart(uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	resp, _, err := o.do("PUT", query.Encode(), "application/octet-stream", data)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(resp.Header.Get("ETag"), "\""), nil
+}
+
+func (o *ufileObject) finishMultipart(uploadID string, etags []string) error {
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	_, _, err := o.do("POST", query.Encode(), "text/plain", []byte(strings.Join(etags, ",")))
+	return err
+}
+
+//uploadFile 分片上传本地文件, 中断后再次上传同一文件时从已完成的分片继续. progress在每个分片完成后调用
+func (o *ufileObject) uploadFile(file string, progress func(done, total int64)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	statePath := fmt.Sprintf("%s/ufile_upload_%x.json", base.GetConfigDir(), md5.Sum([]byte(absPath+"\n"+o.bucket+"\n"+o.key)))
+	state := &ufileMultipartState{}
+	content, err := ioutil.ReadFile(statePath)
+	if err == nil {
+		err = json.Unmarshal(content, state)
+	}
+	if err != nil || state.FileSize != info.Size() || state.ModTime != info.ModTime().Unix() || state.UploadID == "" || state.BlkSize <= 0 {
+		uploadID, blkSize, err := o.initMultipart()
+		if err != nil {
+			return err
+		}
+		state = &ufileMultipartState{
+			Bucket:   o.bucket,
+			Key:      o.key,
+			FileSize: info.Size(),
+			ModTime:  info.ModTime().Unix(),
+			UploadID: uploadID,
+			BlkSize:  blkSize,
+		}
+	}
+	partCount := int((state.FileSize + state.BlkSize - 1) / state.BlkSize)
+	if len(state.ETags) != partCount {
+		state.ETags = make([]string, partCount)
+	}
+
+	buf := make([]byte, state.BlkSize)
+	var done int64
+	for part := 0; part < partCount; part++ {
+		offset := int64(part) * state.BlkSize
+		size := state.BlkSize
+		if offset+size > state.FileSize {
+			size = state.FileSize - offset
+		}
+		if state.ETags[part] == "" {
+			_, err := f.ReadAt(buf[:size], offset)
+			if err != nil {
+				return err
+			}
+			etag, err := o.uploadPart(state.UploadID, part, buf[:size])
+			if isUnknownUpload(err) {
+				os.Remove(statePath)
+				return fmt.Errorf("upload part %d/%d failed: %v. The multipart upload has expired, run the command again to upload from the beginning", part+1, partCount, err)
+			}
+			if err != nil {
+				return fmt.Errorf("upload part %d/%d failed: %v. Run the command again to resume", part+1, partCount, err)
+			}
+			state.ETags[part] = etag
+			content, err := json.Marshal(state)
+			if err != nil {
+				return err
+			}
+			err = ioutil.WriteFile(statePath, content, base.LocalFileMode)
+			if err != nil {
+				return err
+			}
+		}
+		done += size
+		progress(done, state.FileSize)
+	}
+	err = o.finishMultipart(state.UploadID, state.ETags)
+	if isUnknownUpload(err) {
+		os.Remove(statePath)
+		return fmt.Errorf("finish multipart upload failed: %v. The multipart upload has expired, run the command again to upload from the beginning", err)
+	}
+	if err != nil {
+		return err
+	}
+	os.Remove(statePath)
+	return nil
+}
@@ -18,6 +18,10 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -54,10 +58,20 @@ func NewCmdUHost() *cobra.Command {
 	cmd.AddCommand(NewCmdUHostReboot(out))
 	cmd.AddCommand(NewCmdUHostPoweroff(out))
 	cmd.AddCommand(NewCmdUHostResize(out))
+	cmd.AddCommand(NewCmdUHostUpdate(out))
+	cmd.AddCommand(NewCmdUHostEnableDataArk(out))
 	cmd.AddCommand(NewCmdUHostClone(out))
+	cmd.AddCommand(NewCmdUHostTemplate(out))
+	cmd.AddCommand(NewCmdUHostIsolationGroup(out))
+	cmd.AddCommand(NewCmdUHostVnc(out))
+	cmd.AddCommand(NewCmdUHostSSH(out))
+	cmd.AddCommand(NewCmdUHostSSHConfig(out))
+	cmd.AddCommand(NewCmdUHostExec(out))
 	cmd.AddCommand(NewCmdUhostResetPassword(out))
 	cmd.AddCommand(NewCmdUhostReinstallOS(out))
 	cmd.AddCommand(NewCmdUhostCreateImage(out))
+	cmd.AddCommand(NewCmdUHostSnapshot(out))
+	cmd.AddCommand(NewCmdUHostRestoreSnapshotGroup(out))
 
 	return cmd
 }
@@ -71,6 +85,7 @@ type UHostRow struct {
 	PublicIP     string
 	Config       string
 	DiskSet      string
+	DiskBackup   string
 	Zone         string
 	Image        string
 	Type         string
@@ -78,7 +93,7 @@ type UHostRow struct {
 	CreationTime string
 }
 
-func listUhost(uhosts []uhost.UHostInstanceSet, out io.Writer, output string) {
+func uhostRows(uhosts []uhost.UHostInstanceSet, output string) ([]UHostRow, []string) {
 	list := make([]UHostRow, 0)
 	for _, host := range uhosts {
 		row := UHostRow{}
@@ -107,6 +122,7 @@ func listUhost(uhosts []uhost.UHostInstanceSet, out io.Writer, output string) {
 		}
 		row.Zone = host.Zone
 		row.DiskSet = strings.Join(disks, "|")
+		row.DiskBackup = uhostDiskBackup(&host)
 		row.Config = fmt.Sprintf("cpu:%d memory:%dG disk:%dG", cupCore, memorySize, diskSize)
 		row.Image = fmt.Sprintf("%s|%s", host.BasicImageId, host.BasicImageName)
 		row.CreationTime = base.FormatDate(host.CreateTime)
@@ -114,17 +130,10 @@ func listUhost(uhosts []uhost.UHostInstanceSet, out io.Writer, output string) {
 		row.Type = host.MachineType + "/" + host.HostType
 		list = append(list, row)
 	}
-	if global.JSON {
-		base.PrintJSON(list, out)
-	} else {
-		var cols []string
-		if output == "wide" {
-			cols = []string{"UHostName", "ResourceID", "Group", "PrivateIP", "PublicIP", "Config", "DiskSet", "Zone", "Image", "Type", "State", "CreationTime"}
-		} else {
-			cols = []string{"UHostName", "ResourceID", "Group", "PrivateIP", "PublicIP", "Config", "Image", "Type", "State", "CreationTime"}
-		}
-		base.PrintTable(list, cols)
+	if output == "wide" {
+		return list, []string{"UHostName", "ResourceID", "Group", "PrivateIP", "PublicIP", "Config", "DiskSet", "DiskBackup", "Zone", "Image", "Type", "State", "CreationTime"}
 	}
+	return list, []string{"UHostName", "ResourceID", "Group", "PrivateIP", "PublicIP", "Config", "Image", "Type", "State", "CreationTime"}
 }
 
 func listUhostID(uhosts []uhost.UHostInstanceSet, out io.Writer) {
@@ -201,22 +210,30 @@ func getAllUHosts(req *uhost.DescribeUHostInstanceRequest, pageOff bool, allRegi
 func NewCmdUHostList(out io.Writer) *cobra.Command {
 	var allRegion, pageOff, idOnly bool
 	var output string
+	var watch *watchOption
 	req := base.BizClient.NewDescribeUHostInstanceRequest()
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all UHost Instances",
 		Long:  `List all UHost Instances`,
 		Run: func(cmd *cobra.Command, args []string) {
-			uhosts, err := getAllUHosts(req, pageOff, allRegion)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
 			if idOnly {
+				uhosts, err := getAllUHosts(req, pageOff, allRegion)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
 				listUhostID(uhosts, out)
-			} else {
-				listUhost(uhosts, out, output)
+				return
 			}
+			printRows(watch, func() (interface{}, []string, error) {
+				uhosts, err := getAllUHosts(req, pageOff, allRegion)
+				if err != nil {
+					return nil, nil, err
+				}
+				rows, cols := uhostRows(uhosts, output)
+				return rows, cols, nil
+			}, out)
 		},
 	}
 	cmd.Flags().SortFlags = false
@@ -231,6 +248,7 @@ func NewCmdUHostList(out io.Writer) *cobra.Command {
 	cmd.Flags().BoolVar(&idOnly, "uhost-id-only", false, "Optional. Just display resource id of uhost")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Optional. Accept values: wide. Display more information about uhost such as DiskSet and Zone")
 	bindGroup(req, cmd.Flags())
+	watch = bindWatch(cmd.Flags())
 
 	cmd.Flags().SetFlagValues("page-off", "true", "false")
 	cmd.Flags().SetFlagValues("uhost-id-only", "true", "false")
@@ -246,19 +264,95 @@ func NewCmdUHostList(out io.Writer) *cobra.Command {
 
 //NewCmdUHostCreate [ucloud uhost create]
 func NewCmdUHostCreate() *cobra.Command {
-	var bindEipIDs []string
-	var async bool
+	var bindEipIDs, dataDisks []string
+	var async, hotplug bool
 	var count int
+	var keyPair, userData, userDataFile, privateIP string
+	var template, templateFile string
 
 	req := base.BizClient.NewCreateUHostInstanceRequest()
 	eipReq := base.BizClient.NewAllocateEIPRequest()
 	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create UHost instance",
-		Long:  "Create UHost instance",
+		Use:     "create",
+		Short:   "Create UHost instance",
+		Long:    "Create UHost instance",
+		Example: "ucloud uhost create --cpu 2 --memory-gb 4 --image-id uimage-xxx --login-mode KeyPair --key-pair ~/.ssh/id_rsa.pub --user-data-file cloud-init.yaml --data-disk CLOUD_SSD:100 --data-disk CLOUD_NORMAL:500:DATAARK",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if template == "" {
+				return nil
+			}
+			return applyUHostTemplate(cmd.Flags(), templateFile, template)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
+			switch *req.LoginMode {
+			case "Password":
+				if *req.Password == "" {
+					base.Cxt.Println("Error, password is required when login-mode is Password")
+					return
+				}
+			case "KeyPair":
+				if keyPair == "" {
+					base.Cxt.Println("Error, key-pair is required when login-mode is KeyPair")
+					return
+				}
+				publicKey, err := readKeyPair(keyPair)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				req.KeyPair = sdk.String(publicKey)
+				req.Password = nil
+			default:
+				base.Cxt.Printf("Error, login-mode should be Password or KeyPair, got %s\n", *req.LoginMode)
+				return
+			}
+			if userData != "" && userDataFile != "" {
+				base.Cxt.Println("Error, user-data and user-data-file can not be assigned at the same time")
+				return
+			}
+			if userDataFile != "" {
+				content, err := ioutil.ReadFile(userDataFile)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				userData = string(content)
+			}
+			if userData != "" {
+				req.UserDataScript = sdk.String(base64.StdEncoding.EncodeToString([]byte(userData)))
+			}
+			if len(dataDisks) > 0 {
+				if cmd.Flags().Changed("data-disk-type") || cmd.Flags().Changed("data-disk-size-gb") || cmd.Flags().Changed("data-disk-backup-type") {
+					base.Cxt.Println("Error, data-disk can not be used together with data-disk-type, data-disk-size-gb or data-disk-backup-type")
+					return
+				}
+				disks := req.Disks[:1]
+				for _, d := range dataDisks {
+					disk, err := parseDataDisk(d)
+					if err != nil {
+						base.HandleError(err)
+						return
+					}
+					disks = append(disks, disk)
+				}
+				req.Disks = disks
+			}
+			if *req.GPU == 0 {
+				req.GPU = nil
+			}
+			if hotplug {
+				req.HotplugFeature = sdk.Bool(true)
+			}
+			if privateIP != "" {
+				if count > 1 {
+					base.Cxt.Println("Error, private-ip can not be assigned when count is greater than 1")
+					return
+				}
+				req.PrivateIp = []string{privateIP}
+			}
+			req.IsolationGroup = sdk.String(base.PickResourceID(*req.IsolationGroup))
+
 			*req.Memory *= 1024
-			req.LoginMode = sdk.String("Password")
 			req.ImageId = sdk.String(base.PickResourceID(*req.ImageId))
 			req.VPCId = sdk.String(base.PickResourceID(*req.VPCId))
 			req.SubnetId = sdk.String(base.PickResourceID(*req.SubnetId))
@@ -318,8 +412,10 @@ func NewCmdUHostCreate() *cobra.Command {
 	flags.SortFlags = false
 	req.CPU = flags.Int("cpu", 4, "Required. The count of CPU cores. Optional parameters: {1, 2, 4, 8, 12, 16, 24, 32}")
 	req.Memory = flags.Int("memory-gb", 8, "Required. Memory size. Unit: GB. Range: [1, 128], multiple of 2")
-	req.Password = flags.String("password", "", "Required. Password of the uhost user(root/ubuntu)")
+	req.Password = flags.String("password", "", "Optional. Password of the uhost user(root/ubuntu). Required if login-mode is Password")
 	req.ImageId = flags.String("image-id", "", "Required. The ID of image. see 'ucloud image list'")
+	req.LoginMode = flags.String("login-mode", "Password", "Optional. Login mode of the uhost. Accept values: Password, KeyPair")
+	flags.StringVar(&keyPair, "key-pair", "", "Optional. Content of public key, or path of public key file such as ~/.ssh/id_rsa.pub. Required if login-mode is KeyPair")
 	flags.BoolVar(&async, "async", false, "Optional. Do not wait for the long-running operation to finish.")
 	flags.IntVar(&count, "count", 1, "Optional. Number of uhost to create.")
 	req.VPCId = flags.String("vpc-id", "", "Optional. VPC ID. This field is required under VPC2.0. See 'ucloud vpc list'")
@@ -349,10 +445,24 @@ func NewCmdUHostCreate() *cobra.Command {
 	req.Disks[1].Type = flags.String("data-disk-type", "LOCAL_NORMAL", "Optional. Enumeration value. 'LOCAL_NORMAL', Ordinary local disk; 'CLOUD_NORMAL', Ordinary cloud disk; 'LOCAL_SSD',local ssd disk; 'CLOUD_SSD',cloud ssd disk; 'EXCLUSIVE_LOCAL_DISK',big data. The disk only supports a limited combination.")
 	req.Disks[1].Size = flags.Int("data-disk-size-gb", 20, "Optional. Disk size. Unit GB")
 	req.Disks[1].BackupType = flags.String("data-disk-backup-type", "NONE", "Optional. Enumeration value, 'NONE' or 'DATAARK'. DataArk supports real-time backup, which can restore the disk back to any moment within the last 12 hours. (Normal Local Disk and Normal Cloud Disk Only)")
+	flags.StringArrayVar(&dataDisks, "data-disk", nil, "Optional. Data disk in the format of type:size-gb[:backup-type], such as CLOUD_SSD:100 or CLOUD_NORMAL:500:DATAARK. Repeat it to attach multiple data disks. It can not be used together with data-disk-type, data-disk-size-gb and data-disk-backup-type")
 	req.SecurityGroupId = flags.String("firewall-id", "", "Optional. Firewall Id, default: Web recommended firewall. see 'ucloud firewall list'.")
 	req.Tag = flags.String("group", "Default", "Optional. Business group")
+	req.GPU = flags.Int("gpu", 0, "Optional. The count of GPU cores. It takes effect for GPU machine type only")
+	req.GpuType = flags.String("gpu-type", "", "Optional. Accept values: K80, P40, V100. Required if machine-type is G")
+	flags.StringVar(&privateIP, "private-ip", "", "Optional. Private IP of the uhost. It should be in the range of the subnet")
+	req.IsolationGroup = flags.String("isolation-group", "", "Optional. Resource ID of isolation group")
+	flags.BoolVar(&hotplug, "hotplug", false, "Optional. Enable hot-plug feature or not. Accept values: true, false")
+	req.CouponId = flags.String("coupon-id", "", "Optional. Coupon ID. See 'https://accountv2.ucloud.cn'")
+	flags.StringVar(&userData, "user-data", "", "Optional. User data passed to cloud-init, such as shell script or cloud-config. It will be base64 encoded")
+	flags.StringVar(&userDataFile, "user-data-file", "", "Optional. Path of the file which contains user data passed to cloud-init")
+	flags.StringVar(&template, "template", "", "Optional. Name of the template to create uhost from. Parameters assigned in the command line take precedence over the template. See 'ucloud uhost template list'")
+	bindUHostTemplateFile(&templateFile, flags)
 
 	flags.MarkDeprecated("type", "please use --machine-type instead")
+	flags.SetFlagValues("login-mode", "Password", "KeyPair")
+	flags.SetFlagValues("gpu-type", "K80", "P40", "V100")
+	flags.SetFlagValues("hotplug", "true", "false")
 	flags.SetFlagValues("charge-type", "Month", "Year", "Dynamic", "Trial")
 	flags.SetFlagValues("cpu", "1", "2", "4", "8", "12", "16", "24", "32")
 	flags.SetFlagValues("type", "N2", "N1", "N3", "I2", "I1", "C1", "G1", "G2", "G3")
@@ -381,15 +491,80 @@ func NewCmdUHostCreate() *cobra.Command {
 	flags.SetFlagValuesFunc("subnet-id", func() []string {
 		return getAllSubnetIDNames(*req.VPCId, *req.ProjectId, *req.Region)
 	})
+	flags.SetFlagValuesFunc("key-pair", func() []string {
+		return base.GetFileList(".pub")
+	})
+	flags.SetFlagValuesFunc("user-data-file", func() []string {
+		return base.GetFileList("")
+	})
+	flags.SetFlagValuesFunc("isolation-group", func() []string {
+		return getIsolationGroupList(*req.ProjectId, *req.Region)
+	})
+	flags.SetFlagValuesFunc("template", func() []string {
+		return getUHostTemplateNames(templateFile)
+	})
 
 	cmd.MarkFlagRequired("cpu")
 	cmd.MarkFlagRequired("memory-gb")
-	cmd.MarkFlagRequired("password")
 	cmd.MarkFlagRequired("image-id")
 
 	return cmd
 }
 
+//readKeyPair 读取公钥, value为文件路径时读取文件内容
+func readKeyPair(value string) (string, error) {
+	if isPublicKey(value) {
+		return strings.TrimSpace(value), nil
+	}
+	path := value
+	if strings.HasPrefix(path, "~/") {
+		path = filepath.Join(base.GetHomePath(), path[2:])
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("key-pair %q is neither a public key nor a readable file: %v", value, err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+var publicKeyPrefixes = []string{"ssh-", "ecdsa-", "sk-ssh-", "sk-ecdsa-"}
+
+//isPublicKey 判断是否为公钥内容, 如 ssh-rsa AAAA...
+func isPublicKey(value string) bool {
+	value = strings.TrimSpace(value)
+	for _, prefix := range publicKeyPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+//parseDataDisk 解析数据盘 type:size-gb[:backup-type]
+func parseDataDisk(value string) (uhost.UHostDisk, error) {
+	disk := uhost.UHostDisk{
+		IsBoot:     sdk.String("False"),
+		BackupType: sdk.String("NONE"),
+	}
+	fields := strings.Split(value, ":")
+	if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+		return disk, fmt.Errorf("data-disk %q should be in the format of type:size-gb[:backup-type]", value)
+	}
+	size, err := strconv.Atoi(fields[1])
+	if err != nil || size <= 0 {
+		return disk, fmt.Errorf("size of data-disk %q should be a positive integer", value)
+	}
+	disk.Type = sdk.String(strings.ToUpper(fields[0]))
+	disk.Size = sdk.Int(size)
+	if len(fields) == 3 && fields[2] != "" {
+		disk.BackupType = sdk.String(strings.ToUpper(fields[2]))
+	}
+	return disk, nil
+}
+
 //createUhostWrapper 处理UI和并发控制
 func createUhostWrapper(req *uhost.CreateUHostInstanceRequest, eipReq *unet.AllocateEIPRequest, bindEipID string, async bool, retCh chan<- bool, wg *sync.WaitGroup, tokens chan struct{}, idx int) {
 	//控制并发数量
@@ -536,6 +711,7 @@ func NewCmdUHostDelete(out io.Writer) *cobra.Command {
 		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED, status.HOST_FAIL}, *req.ProjectId, *req.Region, *req.Zone)
 	})
 	cmd.MarkFlagRequired("uhost-id")
+	bindFromStdin(cmd, "uhost-id")
 
 	return cmd
 }
@@ -583,13 +759,29 @@ func deleteUHost(creq request.Common) (bool, []string) {
 func NewCmdUHostStop(out io.Writer) *cobra.Command {
 	var uhostIDs *[]string
 	var async *bool
+	var rolling *rollingOption
 	req := base.BizClient.NewStopUHostInstanceRequest()
 	cmd := &cobra.Command{
 		Use:     "stop",
 		Short:   "Shut down uhost instance",
-		Long:    "Shut down uhost instance",
+		Long:    "Shut down uhost instance. With rolling assigned, the uhost instances are disabled in ULB vservers and shut down batch by batch, and stay disabled after shut down",
 		Example: "ucloud uhost stop --uhost-id uhost-xxx1,uhost-xxx2",
 		Run: func(cmd *cobra.Command, args []string) {
+			if rolling.enabled {
+				if *async {
+					base.Cxt.Println("Error, async can not be used together with rolling")
+					return
+				}
+				err := rolling.run(*uhostIDs, *req.ProjectId, *req.Region, false, func(uhostID string) (bool, []string) {
+					_req := *req
+					_req.UHostId = sdk.String(uhostID)
+					return stopUHost(&_req, false)
+				})
+				if err != nil {
+					base.HandleError(err)
+				}
+				return
+			}
 			for _, id := range *uhostIDs {
 				id = base.PickResourceID(id)
 				req.UHostId = &id
@@ -603,10 +795,12 @@ func NewCmdUHostStop(out io.Writer) *cobra.Command {
 	req.Region = cmd.Flags().String("region", base.ConfigIns.Region, "Optional. Assign region")
 	req.Zone = cmd.Flags().String("zone", "", "Optional. Assign availability zone")
 	async = cmd.Flags().Bool("async", false, "Optional. Do not wait for the long-running operation to finish.")
+	rolling = bindRolling(cmd.Flags())
 	cmd.Flags().SetFlagValuesFunc("uhost-id", func() []string {
 		return getUhostList([]string{status.HOST_RUNNING}, *req.ProjectId, *req.Region, *req.Zone)
 	})
 	cmd.MarkFlagRequired("uhost-id")
+	bindFromStdin(cmd, "uhost-id")
 
 	return cmd
 }
@@ -642,6 +836,105 @@ func stopUhostInsV2(req *uhost.StopUHostInstanceRequest, async bool, block *ux.B
 	}
 }
 
+//waitUHostState 等待主机进入目标状态之一，超时或状态不符返回错误, 支持并发
+func waitUHostState(uhostID, project, region, zone, text string, wants []string, block *ux.Block) error {
+	poller := base.NewSpoller(func(id string) (interface{}, error) {
+		return describeUHostByID(id, project, region, zone)
+	}, base.Cxt.GetWriter())
+	ret := poller.Sspoll(uhostID, text, append(wants, status.HOST_FAIL), block)
+	if ret.Timeout {
+		return fmt.Errorf("wait uhost[%s] timeout", uhostID)
+	}
+	if ret.Err != nil {
+		return ret.Err
+	}
+	host, err := describeUHostByID(uhostID, project, region, zone)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("uhost[%s] does not exist", uhostID)
+	}
+	state := host.(*uhost.UHostInstanceSet).State
+	for _, want := range wants {
+		if state == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("uhost[%s] is %s, expected %s", uhostID, state, strings.Join(wants, " or "))
+}
+
+//stopUHost 可并发调用的关机操作
+func stopUHost(req *uhost.StopUHostInstanceRequest, async bool) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:StopUHostInstance, request:%v", base.ToQueryMap(req))}
+	resp, err := base.BizClient.StopUHostInstance(req)
+	if err != nil {
+		text := fmt.Sprintf("stop uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("uhost[%s] is shutting down", resp.UhostId)
+	logs = append(logs, text)
+	if async {
+		block.Append(text)
+		return true, logs
+	}
+	err = waitUHostState(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_STOPPED}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("uhost[%s] stopped", resp.UhostId))
+}
+
+//startUHost 可并发调用的开机操作
+func startUHost(req *uhost.StartUHostInstanceRequest, async bool) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:StartUHostInstance, request:%v", base.ToQueryMap(req))}
+	resp, err := base.BizClient.StartUHostInstance(req)
+	if err != nil {
+		text := fmt.Sprintf("start uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("uhost[%s] is starting", resp.UhostId)
+	logs = append(logs, text)
+	if async {
+		block.Append(text)
+		return true, logs
+	}
+	err = waitUHostState(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("uhost[%s] started", resp.UhostId))
+}
+
+//rebootUHost 可并发调用的重启操作
+func rebootUHost(req *uhost.RebootUHostInstanceRequest) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:RebootUHostInstance, request:%v", base.ToQueryMap(req))}
+	resp, err := base.BizClient.RebootUHostInstance(req)
+	if err != nil {
+		text := fmt.Sprintf("restart uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("uhost[%s] is restarting", resp.UhostId)
+	logs = append(logs, text)
+	err = waitUHostState(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("uhost[%s] restarted", resp.UhostId))
+}
+
 //NewCmdUHostStart ucloud uhost start
 func NewCmdUHostStart(out io.Writer) *cobra.Command {
 	var async *bool
@@ -681,6 +974,7 @@ func NewCmdUHostStart(out io.Writer) *cobra.Command {
 		return getUhostList([]string{status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
 	})
 	cmd.MarkFlagRequired("uhost-id")
+	bindFromStdin(cmd, "uhost-id")
 	return cmd
 }
 
@@ -688,13 +982,29 @@ func NewCmdUHostStart(out io.Writer) *cobra.Command {
 func NewCmdUHostReboot(out io.Writer) *cobra.Command {
 	var uhostIDs *[]string
 	var async *bool
+	var rolling *rollingOption
 	req := base.BizClient.NewRebootUHostInstanceRequest()
 	cmd := &cobra.Command{
 		Use:     "restart",
 		Short:   "Restart uhost instance",
 		Long:    "Restart uhost instance",
-		Example: "ucloud uhost restart --uhost-id uhost-xxx1,uhost-xxx2",
+		Example: "ucloud uhost restart --uhost-id uhost-xxx1,uhost-xxx2,uhost-xxx3 --rolling --batch-size 2 --pause 30s",
 		Run: func(cmd *cobra.Command, args []string) {
+			if rolling.enabled {
+				if *async {
+					base.Cxt.Println("Error, async can not be used together with rolling")
+					return
+				}
+				err := rolling.run(*uhostIDs, *req.ProjectId, *req.Region, true, func(uhostID string) (bool, []string) {
+					_req := *req
+					_req.UHostId = sdk.String(uhostID)
+					return rebootUHost(&_req)
+				})
+				if err != nil {
+					base.HandleError(err)
+				}
+				return
+			}
 			for _, id := range *uhostIDs {
 				id = base.PickResourceID(id)
 				req.UHostId = &id
@@ -720,6 +1030,7 @@ func NewCmdUHostReboot(out io.Writer) *cobra.Command {
 	req.Zone = cmd.Flags().String("zone", "", "Optional. Assign availability zone")
 	req.DiskPassword = cmd.Flags().String("disk-password", "", "Optional. Encrypted disk password")
 	async = cmd.Flags().Bool("async", false, "Optional. Do not wait for the long-running operation to finish.")
+	rolling = bindRolling(cmd.Flags())
 	cmd.Flags().SetFlagValuesFunc("uhost-id", func() []string {
 		return getUhostList([]string{status.HOST_FAIL, status.HOST_RUNNING, status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
 	})
@@ -779,16 +1090,95 @@ func NewCmdUHostPoweroff(out io.Writer) *cobra.Command {
 	return cmd
 }
 
+//NewCmdUHostUpdate ucloud uhost update
+func NewCmdUHostUpdate(out io.Writer) *cobra.Command {
+	var uhostIDs []string
+	var name, remark, group string
+	var startIndex int
+	nameReq := base.BizClient.NewModifyUHostInstanceNameRequest()
+	remarkReq := base.BizClient.NewModifyUHostInstanceRemarkRequest()
+	tagReq := base.BizClient.NewModifyUHostInstanceTagRequest()
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update name, remark or business group of uhost instances",
+		Long: `Update name, remark or business group of uhost instances.
+{index} in name and remark is replaced with the index of the uhost in uhost-id, starting from start-index`,
+		Example: "ucloud uhost update --uhost-id uhost-xxx1,uhost-xxx2 --name web-{index} --group web",
+		Run: func(c *cobra.Command, args []string) {
+			if name == "" && remark == "" && group == "" {
+				fmt.Fprintln(out, "Error, name, remark and group can't be all empty")
+				return
+			}
+			remarkReq.ProjectId, remarkReq.Region, remarkReq.Zone = nameReq.ProjectId, nameReq.Region, nameReq.Zone
+			tagReq.ProjectId, tagReq.Region, tagReq.Zone = nameReq.ProjectId, nameReq.Region, nameReq.Zone
+			for idx, id := range uhostIDs {
+				id = base.PickResourceID(id)
+				index := strconv.Itoa(startIndex + idx)
+				if name != "" {
+					nameReq.UHostId = sdk.String(id)
+					nameReq.Name = sdk.String(strings.Replace(name, "{index}", index, -1))
+					_, err := base.BizClient.ModifyUHostInstanceName(nameReq)
+					if err != nil {
+						base.HandleError(err)
+						continue
+					}
+				}
+				if remark != "" {
+					remarkReq.UHostId = sdk.String(id)
+					remarkReq.Remark = sdk.String(strings.Replace(remark, "{index}", index, -1))
+					_, err := base.BizClient.ModifyUHostInstanceRemark(remarkReq)
+					if err != nil {
+						base.HandleError(err)
+						continue
+					}
+				}
+				if group != "" {
+					tagReq.UHostId = sdk.String(id)
+					tagReq.Tag = sdk.String(group)
+					_, err := base.BizClient.ModifyUHostInstanceTag(tagReq)
+					if err != nil {
+						base.HandleError(err)
+						continue
+					}
+				}
+				fmt.Fprintf(out, "uhost[%s] updated\n", id)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&uhostIDs, "uhost-id", nil, "Required. Resource ID of uhost instances to update")
+	flags.StringVar(&name, "name", "", "Optional. Name of uhost instances, such as web-{index}")
+	flags.StringVar(&remark, "remark", "", "Optional. Remark of uhost instances")
+	flags.StringVar(&group, "group", "", "Optional. Business group of uhost instances")
+	flags.IntVar(&startIndex, "start-index", 1, "Optional. The value of {index} for the first uhost in uhost-id")
+	bindProjectID(nameReq, flags)
+	bindRegion(nameReq, flags)
+	bindZoneEmpty(nameReq, flags)
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED, status.HOST_FAIL}, *nameReq.ProjectId, *nameReq.Region, *nameReq.Zone)
+	})
+	flags.SetFlagValuesFunc("group", func() []string {
+		return getGroupList(*nameReq.ProjectId, *nameReq.Region)
+	})
+	cmd.MarkFlagRequired("uhost-id")
+	bindFromStdin(cmd, "uhost-id")
+	return cmd
+}
+
 //NewCmdUHostResize ucloud uhost resize
 func NewCmdUHostResize(out io.Writer) *cobra.Command {
-	var yes, async *bool
+	var yes, async, restartAfter *bool
 	var uhostIDs *[]string
+	var dataDiskID *string
+	var rolling *rollingOption
 	req := base.BizClient.NewResizeUHostInstanceRequest()
 	cmd := &cobra.Command{
-		Use:     "resize",
-		Short:   "Resize uhost instance,such as cpu core count, memory size and disk size",
-		Long:    "Resize uhost instance,such as cpu core count, memory size and disk size",
-		Example: "ucloud uhost resize --uhost-id uhost-xxx1,uhost-xxx2 --cpu 4 --memory-gb 8",
+		Use:   "resize",
+		Short: "Resize uhost instance,such as cpu core count, memory size and disk size",
+		Long: `Resize uhost instance,such as cpu core count, memory size and disk size.
+The price difference is displayed before confirmation. Local disks are resized with the uhost, and cloud disks are resized online`,
+		Example: "ucloud uhost resize --uhost-id uhost-xxx1,uhost-xxx2 --cpu 4 --memory-gb 8 --restart-after",
 		Run: func(cmd *cobra.Command, args []string) {
 			if *req.CPU == 0 {
 				req.CPU = nil
@@ -804,51 +1194,63 @@ func NewCmdUHostResize(out io.Writer) *cobra.Command {
 			if *req.BootDiskSpace == 0 {
 				req.BootDiskSpace = nil
 			}
+			if *req.NetCapValue == 0 {
+				req.NetCapValue = nil
+			}
+			if *async && (*restartAfter || rolling.enabled) {
+				base.Cxt.Println("Error, async can not be used together with restart-after or rolling")
+				return
+			}
+			plans := make(map[string]*uhostResizePlan)
+			rows := []UHostResizeRow{}
 			for _, id := range *uhostIDs {
 				id = base.PickResourceID(id)
-				req.UHostId = &id
-				host, err := describeUHostByID(id, *req.ProjectId, *req.Region, *req.Zone)
+				plan, err := newUHostResizePlan(req, id, *dataDiskID)
 				if err != nil {
-					base.Cxt.Println(err)
+					base.HandleError(err)
 					return
 				}
-				inst := host.(*uhost.UHostInstanceSet)
-				if inst.State == "Running" {
-					if !*yes {
-						confirmText := "Resize uhost must be after stop it. Do you want to stop this uhost?"
-						if len(*uhostIDs) > 1 {
-							confirmText = "Resize uhost must be after stop it. Do you want to stop those uhosts?"
-						}
-						agreeClose, err := ux.Prompt(confirmText)
-						if err != nil {
-							base.Cxt.Println(err)
-							return
-						}
-						if !agreeClose {
-							continue
+				plan.restart = *restartAfter || rolling.enabled
+				plan.async = *async
+				plans[id] = plan
+				rows = append(rows, plan.row)
+			}
+			base.PrintList(rows, out)
+			if !*yes {
+				confirmText := "Do you want to resize the uhost(s)?"
+				for _, plan := range plans {
+					if plan.resizeReq != nil && plan.inst.State == status.HOST_RUNNING {
+						confirmText = "Resize uhost must be after stop it. Running uhosts will be stopped. Do you want to continue?"
+						if plan.restart {
+							confirmText = "Resize uhost must be after stop it. Running uhosts will be stopped, resized and started again. Do you want to continue?"
 						}
+						break
 					}
-					_req := base.BizClient.NewStopUHostInstanceRequest()
-					_req.ProjectId = req.ProjectId
-					_req.Region = req.Region
-					_req.Zone = req.Zone
-					_req.UHostId = &id
-					stopUhostIns(_req, false, out)
 				}
-
-				resp, err := base.BizClient.ResizeUHostInstance(req)
+				sure, err := ux.Prompt(confirmText)
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			if rolling.enabled {
+				err := rolling.run(*uhostIDs, *req.ProjectId, *req.Region, true, func(uhostID string) (bool, []string) {
+					return resizeUHostByPlan(plans[uhostID])
+				})
 				if err != nil {
 					base.HandleError(err)
-				} else {
-					text := fmt.Sprintf("UHost:[%v] resized", resp.UhostId)
-					if *async {
-						fmt.Fprintln(out, text)
-					} else {
-						poller := base.NewPoller(describeUHostByID, out)
-						poller.Poll(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING, status.HOST_STOPPED, status.HOST_FAIL})
-					}
 				}
+				return
+			}
+			reqs := []request.Common{}
+			for _, row := range rows {
+				reqs = append(reqs, plans[row.ResourceID])
 			}
+			coAction := newConcurrentAction(reqs, resizeUHostByPlan)
+			coAction.Do()
 		},
 	}
 	cmd.Flags().SortFlags = false
@@ -859,17 +1261,100 @@ func NewCmdUHostResize(out io.Writer) *cobra.Command {
 	req.CPU = cmd.Flags().Int("cpu", 0, "Optional. The number of virtual CPU cores. Series1 {1, 2, 4, 8, 12, 16, 24, 32}. Series2 {1,2,4,8,16}")
 	req.Memory = cmd.Flags().Int("memory-gb", 0, "Optional. memory size. Unit: GB. Range: [1, 128], multiple of 2")
 	req.DiskSpace = cmd.Flags().Int("data-disk-size-gb", 0, "Optional. Data disk size,unit GB. Range[10,1000], SSD disk range[100,500]. Step 10")
+	dataDiskID = cmd.Flags().String("data-disk-id", "", "Optional. Resource ID of the data disk to resize. Required if the uhost has more than one data disk")
 	req.BootDiskSpace = cmd.Flags().Int("system-disk-size-gb", 0, "Optional. System disk size, unit GB. Range[20,100]. Step 10. System disk does not support shrinkage")
 	req.NetCapValue = cmd.Flags().Int("net-cap", 0, "Optional. NIC scale. 1,upgrade; 2,downgrade; 0,unchanged")
+	restartAfter = cmd.Flags().Bool("restart-after", false, "Optional. Start the uhost instances which are stopped for resizing after resized, so that they return to their prior state")
 	yes = cmd.Flags().BoolP("yes", "y", false, "Optional. Do not prompt for confirmation.")
 	async = cmd.Flags().BoolP("async", "a", false, "Optional. Do not wait for the long-running operation to finish.")
+	rolling = bindRolling(cmd.Flags())
+	cmd.Flags().SetFlagValues("restart-after", "true", "false")
 	cmd.Flags().SetFlagValuesFunc("uhost-id", func() []string {
 		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED, status.HOST_FAIL}, *req.ProjectId, *req.Region, *req.Zone)
 	})
 	cmd.MarkFlagRequired("uhost-id")
+	bindFromStdin(cmd, "uhost-id")
 	return cmd
 }
 
+//resizeUHost 可并发调用的调整配置操作，主机运行中会先关机
+func resizeUHost(req *uhost.ResizeUHostInstanceRequest, async bool) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{}
+	host, err := describeUHostByID(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone)
+	if err != nil {
+		text := fmt.Sprintf("describe uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if host == nil {
+		text := fmt.Sprintf("uhost[%s] does not exist", *req.UHostId)
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if host.(*uhost.UHostInstanceSet).State == status.HOST_RUNNING {
+		stopReq := base.BizClient.NewStopUHostInstanceRequest()
+		stopReq.ProjectId = req.ProjectId
+		stopReq.Region = req.Region
+		stopReq.Zone = req.Zone
+		stopReq.UHostId = req.UHostId
+		logs = append(logs, fmt.Sprintf("api:StopUHostInstance, request:%v", base.ToQueryMap(stopReq)))
+		_, err := base.BizClient.StopUHostInstance(stopReq)
+		if err != nil {
+			text := fmt.Sprintf("stop uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+			block.Append(text)
+			return false, append(logs, text)
+		}
+		text := fmt.Sprintf("uhost[%s] is shutting down", *req.UHostId)
+		err = waitUHostState(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_STOPPED}, block)
+		if err != nil {
+			block.Append(err.Error())
+			return false, append(logs, err.Error())
+		}
+	}
+
+	logs = append(logs, fmt.Sprintf("api:ResizeUHostInstance, request:%v", base.ToQueryMap(req)))
+	resp, err := base.BizClient.ResizeUHostInstance(req)
+	if err != nil {
+		text := fmt.Sprintf("resize uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("uhost[%s] resized", resp.UhostId)
+	logs = append(logs, text)
+	if async {
+		block.Append(text)
+		return true, logs
+	}
+	err = waitUHostState(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING, status.HOST_STOPPED}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, logs
+}
+
+//resizeAndStartUHost 可并发调用的调整配置操作，主机原本运行中时调整后会再开机
+func resizeAndStartUHost(req *uhost.ResizeUHostInstanceRequest) (bool, []string) {
+	running := false
+	host, err := describeUHostByID(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone)
+	if err == nil && host != nil {
+		running = host.(*uhost.UHostInstanceSet).State == status.HOST_RUNNING
+	}
+	success, logs := resizeUHost(req, false)
+	if !success || !running {
+		return success, logs
+	}
+	startReq := base.BizClient.NewStartUHostInstanceRequest()
+	startReq.ProjectId = req.ProjectId
+	startReq.Region = req.Region
+	startReq.Zone = req.Zone
+	startReq.UHostId = req.UHostId
+	success, _logs := startUHost(startReq, false)
+	return success, append(logs, _logs...)
+}
+
 func describeUHostByID(uhostID, projectID, region, zone string) (interface{}, error) {
 	req := base.BizClient.NewDescribeUHostInstanceRequest()
 	req.UHostIds = []string{uhostID}
@@ -925,6 +1410,65 @@ func getUhostList(states []string, project, region, zone string) []string {
 	return list
 }
 
+//fillCloneRequest 按照已有主机的配置填充创建主机的请求, 不包括绑定的eip和udisk
+func fillCloneRequest(req *uhost.CreateUHostInstanceRequest, uhostID string) (*uhost.UHostInstanceSet, error) {
+	queryReq := base.BizClient.NewDescribeUHostInstanceRequest()
+	queryReq.ProjectId = req.ProjectId
+	queryReq.Region = req.Region
+	queryReq.Zone = req.Zone
+	queryReq.UHostIds = []string{uhostID}
+	queryResp, err := base.BizClient.DescribeUHostInstance(queryReq)
+	if err != nil {
+		return nil, err
+	}
+	if len(queryResp.UHostSet) < 1 {
+		return nil, fmt.Errorf("uhost[%s] not exist", uhostID)
+	}
+	queryFirewallReq := base.BizClient.NewDescribeFirewallRequest()
+	queryFirewallReq.ProjectId = req.ProjectId
+	queryFirewallReq.Region = req.Region
+	queryFirewallReq.ResourceId = sdk.String(uhostID)
+	queryFirewallReq.ResourceType = sdk.String("uhost")
+
+	firewallResp, err := base.BizClient.DescribeFirewall(queryFirewallReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(firewallResp.DataSet) == 1 {
+		req.SecurityGroupId = &firewallResp.DataSet[0].FWId
+	}
+
+	uhostIns := queryResp.UHostSet[0]
+
+	req.ImageId = &uhostIns.BasicImageId
+	req.CPU = &uhostIns.CPU
+	req.Memory = &uhostIns.Memory
+	for _, ip := range uhostIns.IPSet {
+		if ip.Type == "Private" {
+			req.VPCId = &ip.VPCId
+			req.SubnetId = &ip.SubnetId
+		}
+	}
+	req.ChargeType = &uhostIns.ChargeType
+	req.UHostType = &uhostIns.UHostType
+	req.NetCapability = &uhostIns.NetCapability
+
+	for _, disk := range uhostIns.DiskSet {
+		item := uhost.UHostDisk{
+			Size:   sdk.Int(disk.Size),
+			Type:   sdk.String(disk.DiskType),
+			IsBoot: sdk.String(disk.IsBoot),
+		}
+		if disk.BackupType != "" {
+			item.BackupType = sdk.String(disk.BackupType)
+		}
+		req.Disks = append(req.Disks, item)
+	}
+	req.Tag = &uhostIns.Tag
+	return &uhostIns, nil
+}
+
 //NewCmdUHostClone ucloud uhost clone
 func NewCmdUHostClone(out io.Writer) *cobra.Command {
 	var uhostID *string
@@ -936,63 +1480,11 @@ func NewCmdUHostClone(out io.Writer) *cobra.Command {
 		Long:  "Create an uhost with the same configuration as another uhost, excluding bound eip and udisk",
 		Run: func(com *cobra.Command, args []string) {
 			*uhostID = base.PickResourceID(*uhostID)
-			queryReq := base.BizClient.NewDescribeUHostInstanceRequest()
-			queryReq.ProjectId = req.ProjectId
-			queryReq.Region = req.Region
-			queryReq.Zone = req.Zone
-			queryReq.UHostIds = []string{*uhostID}
-			queryResp, err := base.BizClient.DescribeUHostInstance(queryReq)
+			_, err := fillCloneRequest(req, *uhostID)
 			if err != nil {
 				base.HandleError(err)
 				return
 			}
-			if len(queryResp.UHostSet) < 1 {
-				base.Cxt.PrintErr(fmt.Errorf("uhost[%s] not exist", *uhostID))
-				return
-			}
-			queryFirewallReq := base.BizClient.NewDescribeFirewallRequest()
-			queryFirewallReq.ProjectId = req.ProjectId
-			queryFirewallReq.Region = req.Region
-			queryFirewallReq.ResourceId = uhostID
-			queryFirewallReq.ResourceType = sdk.String("uhost")
-
-			firewallResp, err := base.BizClient.DescribeFirewall(queryFirewallReq)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
-
-			if len(firewallResp.DataSet) == 1 {
-				req.SecurityGroupId = &firewallResp.DataSet[0].FWId
-			}
-
-			uhostIns := queryResp.UHostSet[0]
-
-			req.ImageId = &uhostIns.BasicImageId
-			req.CPU = &uhostIns.CPU
-			req.Memory = &uhostIns.Memory
-			for _, ip := range uhostIns.IPSet {
-				if ip.Type == "Private" {
-					req.VPCId = &ip.VPCId
-					req.SubnetId = &ip.SubnetId
-				}
-			}
-			req.ChargeType = &uhostIns.ChargeType
-			req.UHostType = &uhostIns.UHostType
-			req.NetCapability = &uhostIns.NetCapability
-
-			for _, disk := range uhostIns.DiskSet {
-				item := uhost.UHostDisk{
-					Size:   sdk.Int(disk.Size),
-					Type:   sdk.String(disk.DiskType),
-					IsBoot: sdk.String(disk.IsBoot),
-				}
-				if disk.BackupType != "" {
-					item.BackupType = sdk.String(disk.BackupType)
-				}
-				req.Disks = append(req.Disks, item)
-			}
-			req.Tag = &uhostIns.Tag
 			req.LoginMode = sdk.String("Password")
 			resp, err := base.BizClient.CreateUHostInstance(req)
 			if err != nil {
@@ -1161,6 +1653,8 @@ func checkAndCloseUhost(yes, async bool, uhostID, project, region, zone string,
 //NewCmdUhostReinstallOS ucloud uhost reinstall-os
 func NewCmdUhostReinstallOS(out io.Writer) *cobra.Command {
 	var isReserveDataDisk, yes, async *bool
+	var uhostIDs *[]string
+	var rolling *rollingOption
 	req := base.BizClient.NewReinstallUHostInstanceRequest()
 	cmd := &cobra.Command{
 		Use:   "reinstall-os",
@@ -1172,67 +1666,41 @@ func NewCmdUhostReinstallOS(out io.Writer) *cobra.Command {
 			} else {
 				req.ReserveDisk = sdk.String("No")
 			}
-			req.UHostId = sdk.String(base.PickResourceID(*req.UHostId))
 			req.Password = sdk.String(base64.StdEncoding.EncodeToString([]byte(sdk.StringValue(req.Password))))
-
-			any, err := describeUHostByID(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone)
-			if err != nil {
-				base.Cxt.Println(err)
-				return
-			}
-			uhostIns, ok := any.(*uhost.UHostInstanceSet)
-			if ok {
-				for _, disk := range uhostIns.DiskSet {
-					if disk.Type == "Udisk" {
-						sure := false
-						if !*yes {
-							text := fmt.Sprintf("udisk[%s/%s] will be detached, can we do this?", disk.DiskId, disk.Name)
-							sure, err = ux.Prompt(text)
-							if err != nil {
-								base.Cxt.PrintErr(err)
-								return
-							}
-							if !sure {
-								base.Cxt.Printf("you don't agree to detach udisk\n")
-								return
-							}
-						}
-						if *yes || sure {
-							err := detachUdisk(false, disk.DiskId, out)
-							if err != nil {
-								base.Cxt.Println(err)
-								return
-							}
-						}
+			if rolling.enabled {
+				if *async {
+					base.Cxt.Println("Error, async can not be used together with rolling")
+					return
+				}
+				if !*yes {
+					sure, err := ux.Prompt("Udisks attached to the uhosts will be detached, and running uhosts will be stopped batch by batch. Do you want to continue?")
+					if err != nil {
+						base.Cxt.Println(err)
+						return
+					}
+					if !sure {
+						return
 					}
 				}
-			} else {
-				base.Cxt.Printf("Something wrong, uhost[%s] may not exist\n", *req.UHostId)
-				return
-			}
-
-			err = checkAndCloseUhost(*yes, *async, *req.UHostId, *req.ProjectId, *req.Region, *req.Zone, out)
-			if err != nil {
-				base.Cxt.Println(err)
-				return
-			}
-			resp, err := base.BizClient.ReinstallUHostInstance(req)
-			if err != nil {
-				base.Cxt.Println(err)
+				err := rolling.run(*uhostIDs, *req.ProjectId, *req.Region, true, func(uhostID string) (bool, []string) {
+					_req := *req
+					_req.UHostId = sdk.String(uhostID)
+					return reinstallUHost(&_req)
+				})
+				if err != nil {
+					base.HandleError(err)
+				}
 				return
 			}
-			text := fmt.Sprintf("uhost[%s] is reinstalling OS", *req.UHostId)
-			if *async {
-				fmt.Fprintln(out, text)
-			} else {
-				poller := base.NewPoller(describeUHostByID, out)
-				poller.Poll(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING, status.HOST_FAIL})
+			for _, id := range *uhostIDs {
+				req.UHostId = sdk.String(base.PickResourceID(id))
+				reinstallUHostIns(req, *yes, *async, out)
 			}
 		},
 	}
 	flags := cmd.Flags()
 	flags.SortFlags = false
-	req.UHostId = flags.String("uhost-id", "", "Required. Resource ID of the uhost to reinstall operating system")
+	uhostIDs = flags.StringSlice("uhost-id", nil, "Required. Resource ID of the uhosts to reinstall operating system")
 	req.Password = flags.String("password", "", "Required. Password of the administrator")
 	req.ImageId = flags.String("image-id", "", "Optional. Resource ID the image to install. See 'ucloud image list'. Default is original image of the uhost")
 	req.ProjectId = flags.String("project-id", base.ConfigIns.ProjectID, "Optional. Assign project-id")
@@ -1241,6 +1709,7 @@ func NewCmdUhostReinstallOS(out io.Writer) *cobra.Command {
 	isReserveDataDisk = flags.Bool("keep-data-disk", false, "Keep data disk or not. If you keep data disk, you can't change OS type(Linux->Window,e.g.)")
 	yes = cmd.Flags().BoolP("yes", "y", false, "Optional. Do not prompt for confirmation.")
 	async = flags.BoolP("async", "a", false, "Optional. Do not wait for the long-running operation to finish.")
+	rolling = bindRolling(flags)
 	flags.SetFlagValuesFunc("uhost-id", func() []string {
 		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
 	})
@@ -1248,3 +1717,141 @@ func NewCmdUhostReinstallOS(out io.Writer) *cobra.Command {
 	cmd.MarkFlagRequired("password")
 	return cmd
 }
+
+func reinstallUHostIns(req *uhost.ReinstallUHostInstanceRequest, yes, async bool, out io.Writer) {
+	any, err := describeUHostByID(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone)
+	if err != nil {
+		base.Cxt.Println(err)
+		return
+	}
+	uhostIns, ok := any.(*uhost.UHostInstanceSet)
+	if ok {
+		for _, disk := range uhostIns.DiskSet {
+			if disk.Type == "Udisk" {
+				sure := false
+				if !yes {
+					text := fmt.Sprintf("udisk[%s/%s] will be detached, can we do this?", disk.DiskId, disk.Name)
+					sure, err = ux.Prompt(text)
+					if err != nil {
+						base.Cxt.PrintErr(err)
+						return
+					}
+					if !sure {
+						base.Cxt.Printf("you don't agree to detach udisk\n")
+						return
+					}
+				}
+				if yes || sure {
+					err := detachUdisk(false, disk.DiskId, out)
+					if err != nil {
+						base.Cxt.Println(err)
+						return
+					}
+				}
+			}
+		}
+	} else {
+		base.Cxt.Printf("Something wrong, uhost[%s] may not exist\n", *req.UHostId)
+		return
+	}
+
+	err = checkAndCloseUhost(yes, async, *req.UHostId, *req.ProjectId, *req.Region, *req.Zone, out)
+	if err != nil {
+		base.Cxt.Println(err)
+		return
+	}
+	resp, err := base.BizClient.ReinstallUHostInstance(req)
+	if err != nil {
+		base.Cxt.Println(err)
+		return
+	}
+	text := fmt.Sprintf("uhost[%s] is reinstalling OS", *req.UHostId)
+	if async {
+		fmt.Fprintln(out, text)
+	} else {
+		poller := base.NewPoller(describeUHostByID, out)
+		poller.Poll(resp.UhostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING, status.HOST_FAIL})
+	}
+}
+
+//reinstallUHost 可并发调用的重装系统操作，会先卸载云硬盘，主机运行中会先关机
+func reinstallUHost(req *uhost.ReinstallUHostInstanceRequest) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{}
+	host, err := describeUHostByID(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone)
+	if err != nil {
+		text := fmt.Sprintf("describe uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if host == nil {
+		text := fmt.Sprintf("uhost[%s] does not exist", *req.UHostId)
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	inst := host.(*uhost.UHostInstanceSet)
+	for _, disk := range inst.DiskSet {
+		if disk.Type != "Udisk" {
+			continue
+		}
+		detachReq := base.BizClient.NewDetachUDiskRequest()
+		detachReq.ProjectId = req.ProjectId
+		detachReq.Region = req.Region
+		detachReq.Zone = req.Zone
+		detachReq.UHostId = req.UHostId
+		detachReq.UDiskId = sdk.String(disk.DiskId)
+		logs = append(logs, fmt.Sprintf("api:DetachUDisk, request:%v", base.ToQueryMap(detachReq)))
+		_, err := base.BizClient.DetachUDisk(detachReq)
+		if err != nil {
+			text := fmt.Sprintf("detach udisk[%s] from uhost[%s] failed: %s", disk.DiskId, *req.UHostId, base.ParseError(err))
+			block.Append(text)
+			return false, append(logs, text)
+		}
+		text := fmt.Sprintf("udisk[%s] is detaching from uhost[%s]", disk.DiskId, *req.UHostId)
+		logs = append(logs, text)
+		poller := base.NewSpoller(describeUdiskByID, base.Cxt.GetWriter())
+		ret := poller.Sspoll(disk.DiskId, text, []string{status.DISK_AVAILABLE, status.DISK_FAILED}, block)
+		if ret.Timeout || ret.Err != nil {
+			text := fmt.Sprintf("detach udisk[%s] from uhost[%s] failed", disk.DiskId, *req.UHostId)
+			block.Append(text)
+			return false, append(logs, text)
+		}
+	}
+	if inst.State == status.HOST_RUNNING {
+		stopReq := base.BizClient.NewStopUHostInstanceRequest()
+		stopReq.ProjectId = req.ProjectId
+		stopReq.Region = req.Region
+		stopReq.Zone = req.Zone
+		stopReq.UHostId = req.UHostId
+		logs = append(logs, fmt.Sprintf("api:StopUHostInstance, request:%v", base.ToQueryMap(stopReq)))
+		_, err := base.BizClient.StopUHostInstance(stopReq)
+		if err != nil {
+			text := fmt.Sprintf("stop uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+			block.Append(text)
+			return false, append(logs, text)
+		}
+		text := fmt.Sprintf("uhost[%s] is shutting down", *req.UHostId)
+		err = waitUHostState(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_STOPPED}, block)
+		if err != nil {
+			block.Append(err.Error())
+			return false, append(logs, err.Error())
+		}
+	}
+
+	logs = append(logs, fmt.Sprintf("api:ReinstallUHostInstance, request:%v", base.ToQueryMap(req)))
+	_, err = base.BizClient.ReinstallUHostInstance(req)
+	if err != nil {
+		text := fmt.Sprintf("reinstall uhost[%s] failed: %s", *req.UHostId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("uhost[%s] is reinstalling OS", *req.UHostId)
+	logs = append(logs, text)
+	err = waitUHostState(*req.UHostId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.HOST_RUNNING}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("uhost[%s] reinstalled", *req.UHostId))
+}
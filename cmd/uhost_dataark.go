@@ -0,0 +1,207 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//UHostDataArkRow 开通数据方舟的价格表格行
+type UHostDataArkRow struct {
+	ResourceID string
+	Name       string
+	State      string
+	DiskBackup string
+	Price      string
+}
+
+//NewCmdUHostEnableDataArk ucloud uhost enable-dataark
+func NewCmdUHostEnableDataArk(out io.Writer) *cobra.Command {
+	var uhostIDs []string
+	var yes, async bool
+	req := base.BizClient.NewUpgradeToArkUHostInstanceRequest()
+	cmd := &cobra.Command{
+		Use:     "enable-dataark",
+		Short:   "Enable data ark(DataArk) backup for uhost instances",
+		Long:    "Enable data ark(DataArk) backup for uhost instances. The price of data ark is displayed before confirmation",
+		Example: "ucloud uhost enable-dataark --uhost-id uhost-xxx1,uhost-xxx2",
+		Run: func(c *cobra.Command, args []string) {
+			if *req.CouponId == "" {
+				req.CouponId = nil
+			}
+			rows := []UHostDataArkRow{}
+			reqs := []request.Common{}
+			for _, id := range uhostIDs {
+				id = base.PickResourceID(id)
+				host, err := describeUHostByID(id, *req.ProjectId, *req.Region, *req.Zone)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				if host == nil {
+					base.Cxt.Printf("Error, uhost[%s] does not exist\n", id)
+					return
+				}
+				inst := host.(*uhost.UHostInstanceSet)
+				row := UHostDataArkRow{
+					ResourceID: inst.UHostId,
+					Name:       inst.Name,
+					State:      inst.State,
+					DiskBackup: uhostDiskBackup(inst),
+				}
+				if uhostArkState(inst) == uhostArkEnabled {
+					row.Price = "enabled already"
+					rows = append(rows, row)
+					continue
+				}
+				priceReq := base.BizClient.NewGetUHostUpgradePriceRequest()
+				priceReq.ProjectId = req.ProjectId
+				priceReq.Region = req.Region
+				priceReq.Zone = req.Zone
+				priceReq.UHostId = sdk.String(id)
+				priceReq.TimemachineFeature = sdk.String("Yes")
+				resp, err := base.BizClient.GetUHostUpgradePrice(priceReq)
+				if err != nil {
+					base.LogError(fmt.Sprintf("get data ark price of uhost[%s] failed: %s", id, base.ParseError(err)))
+					row.Price = "unknown"
+				} else {
+					row.Price = fmt.Sprintf("%.2f", resp.Price)
+				}
+				rows = append(rows, row)
+				_req := *req
+				_req.UHostIds = []string{id}
+				reqs = append(reqs, &_req)
+			}
+			base.PrintList(rows, out)
+			if len(reqs) == 0 {
+				return
+			}
+			if !yes {
+				sure, err := ux.Prompt("Do you want to enable data ark for the uhost(s)?")
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			coAction := newConcurrentAction(reqs, func(creq request.Common) (bool, []string) {
+				return enableUHostDataArk(creq.(*uhost.UpgradeToArkUHostInstanceRequest), async)
+			})
+			coAction.Do()
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&uhostIDs, "uhost-id", nil, "Required. Resource ID of uhost instances to enable data ark")
+	req.CouponId = flags.String("coupon-id", "", "Optional. Coupon ID. See 'https://accountv2.ucloud.cn'")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation.")
+	flags.BoolVarP(&async, "async", "a", false, "Optional. Do not wait for the long-running operation to finish.")
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	cmd.MarkFlagRequired("uhost-id")
+	bindFromStdin(cmd, "uhost-id")
+	return cmd
+}
+
+const (
+	uhostArkEnabled  = "Enabled"
+	uhostArkDisabled = "Disabled"
+	uhostArkPending  = "Pending"
+)
+
+//uhostArkState 数据方舟状态, 开通后系统盘备份方式变为DATAARK且系统盘状态恢复正常
+func uhostArkState(inst *uhost.UHostInstanceSet) string {
+	if inst.TimemachineFeature != "Yes" {
+		return uhostArkDisabled
+	}
+	for _, disk := range inst.DiskSet {
+		if disk.IsBoot == "True" && disk.BackupType != "DATAARK" {
+			return uhostArkPending
+		}
+	}
+	if inst.BootDiskState != "" && inst.BootDiskState != "Normal" {
+		return uhostArkPending
+	}
+	return uhostArkEnabled
+}
+
+//uhostDiskBackup 各磁盘的备份方式, 与DiskSet的顺序一致
+func uhostDiskBackup(inst *uhost.UHostInstanceSet) string {
+	backups := []string{}
+	for _, disk := range inst.DiskSet {
+		backup := disk.BackupType
+		if backup == "" {
+			backup = "NONE"
+		}
+		backups = append(backups, fmt.Sprintf("%s:%s", disk.Type, backup))
+	}
+	return strings.Join(backups, "|")
+}
+
+//enableUHostDataArk 可并发调用, 开通数据方舟并等待状态稳定
+func enableUHostDataArk(req *uhost.UpgradeToArkUHostInstanceRequest, async bool) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	id := req.UHostIds[0]
+	logs := []string{fmt.Sprintf("api:UpgradeToArkUHostInstance, request:%v", base.ToQueryMap(req))}
+	_, err := base.BizClient.UpgradeToArkUHostInstance(req)
+	if err != nil {
+		text := fmt.Sprintf("enable data ark for uhost[%s] failed: %s", id, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("uhost[%s] is enabling data ark", id)
+	logs = append(logs, text)
+	if async {
+		block.Append(text)
+		return true, logs
+	}
+	poller := base.NewSpoller(func(uhostID string) (interface{}, error) {
+		host, err := describeUHostByID(uhostID, *req.ProjectId, *req.Region, *req.Zone)
+		if err != nil || host == nil {
+			return nil, err
+		}
+		return &struct{ State string }{uhostArkState(host.(*uhost.UHostInstanceSet))}, nil
+	}, base.Cxt.GetWriter())
+	ret := poller.Sspoll(id, text, []string{uhostArkEnabled}, block)
+	if ret.Timeout {
+		text := fmt.Sprintf("wait data ark of uhost[%s] timeout", id)
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if ret.Err != nil {
+		block.Append(ret.Err.Error())
+		return false, append(logs, ret.Err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("data ark of uhost[%s] enabled", id))
+}
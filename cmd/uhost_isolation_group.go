@@ -0,0 +1,228 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//NewCmdUHostIsolationGroup ucloud uhost isolation-group
+func NewCmdUHostIsolationGroup(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "isolation-group",
+		Short: "Create, list, delete isolation groups and remove uhost from isolation group",
+		Long:  "Create, list, delete isolation groups and remove uhost from isolation group. Uhost instances in the same isolation group are spread across different physical hosts",
+	}
+	cmd.AddCommand(NewCmdIsolationGroupCreate(out))
+	cmd.AddCommand(NewCmdIsolationGroupList(out))
+	cmd.AddCommand(NewCmdIsolationGroupDelete(out))
+	cmd.AddCommand(NewCmdIsolationGroupLeave(out))
+	return cmd
+}
+
+//IsolationGroupRow 硬件隔离组表格行
+type IsolationGroupRow struct {
+	ResourceID string
+	Name       string
+	UHostCount int
+	Spread     string
+	Remark     string
+}
+
+//NewCmdIsolationGroupCreate ucloud uhost isolation-group create
+func NewCmdIsolationGroupCreate(out io.Writer) *cobra.Command {
+	req := base.BizClient.NewCreateIsolationGroupRequest()
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create isolation group",
+		Long:    "Create isolation group",
+		Example: "ucloud uhost isolation-group create --name web",
+		Run: func(c *cobra.Command, args []string) {
+			resp, err := base.BizClient.CreateIsolationGroup(req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			fmt.Fprintf(out, "isolation group[%s] created\n", resp.GroupId)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	req.GroupName = flags.String("name", "", "Required. Name of the isolation group")
+	req.Remark = flags.String("remark", "", "Optional. Remark of the isolation group")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+//NewCmdIsolationGroupList ucloud uhost isolation-group list
+func NewCmdIsolationGroupList(out io.Writer) *cobra.Command {
+	req := base.BizClient.NewDescribeIsolationGroupRequest()
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List isolation groups and the count of uhost instances in each zone",
+		Long:  "List isolation groups and the count of uhost instances in each zone",
+		Run: func(c *cobra.Command, args []string) {
+			if *req.GroupId != "" {
+				*req.GroupId = base.PickResourceID(*req.GroupId)
+			}
+			resp, err := base.BizClient.DescribeIsolationGroup(req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			list := []IsolationGroupRow{}
+			for _, group := range resp.IsolationGroupSet {
+				row := IsolationGroupRow{
+					ResourceID: group.GroupId,
+					Name:       group.GroupName,
+					Remark:     group.Remark,
+				}
+				spread := []string{}
+				for _, info := range group.SpreadInfoSet {
+					row.UHostCount += info.UHostCount
+					spread = append(spread, fmt.Sprintf("%s:%d", info.Zone, info.UHostCount))
+				}
+				row.Spread = strings.Join(spread, " ")
+				list = append(list, row)
+			}
+			base.PrintList(list, out)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	req.GroupId = flags.String("isolation-group-id", "", "Optional. Resource ID of isolation group. List all isolation groups by default")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindOffset(req, flags)
+	bindLimit(req, flags)
+	flags.SetFlagValuesFunc("isolation-group-id", func() []string {
+		return getIsolationGroupList(*req.ProjectId, *req.Region)
+	})
+	return cmd
+}
+
+//NewCmdIsolationGroupDelete ucloud uhost isolation-group delete
+func NewCmdIsolationGroupDelete(out io.Writer) *cobra.Command {
+	var groupIDs []string
+	var yes bool
+	req := base.BizClient.NewDeleteIsolationGroupRequest()
+	cmd := &cobra.Command{
+		Use:     "delete",
+		Short:   "Delete isolation groups",
+		Long:    "Delete isolation groups. The isolation group should contain no uhost instance",
+		Example: "ucloud uhost isolation-group delete --isolation-group-id ig-xxx",
+		Run: func(c *cobra.Command, args []string) {
+			if !yes {
+				sure, err := ux.Prompt("Are you sure you want to delete the isolation group(s)?")
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			for _, id := range groupIDs {
+				req.GroupId = sdk.String(base.PickResourceID(id))
+				_, err := base.BizClient.DeleteIsolationGroup(req)
+				if err != nil {
+					base.HandleError(err)
+					continue
+				}
+				fmt.Fprintf(out, "isolation group[%s] deleted\n", *req.GroupId)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&groupIDs, "isolation-group-id", nil, "Required. Resource ID of isolation groups to delete")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation")
+	flags.SetFlagValuesFunc("isolation-group-id", func() []string {
+		return getIsolationGroupList(*req.ProjectId, *req.Region)
+	})
+	cmd.MarkFlagRequired("isolation-group-id")
+	return cmd
+}
+
+//NewCmdIsolationGroupLeave ucloud uhost isolation-group leave
+func NewCmdIsolationGroupLeave(out io.Writer) *cobra.Command {
+	var uhostIDs []string
+	req := base.BizClient.NewLeaveIsolationGroupRequest()
+	cmd := &cobra.Command{
+		Use:     "leave",
+		Short:   "Remove uhost instances from isolation group",
+		Long:    "Remove uhost instances from isolation group",
+		Example: "ucloud uhost isolation-group leave --isolation-group-id ig-xxx --uhost-id uhost-xxx",
+		Run: func(c *cobra.Command, args []string) {
+			*req.GroupId = base.PickResourceID(*req.GroupId)
+			for _, id := range uhostIDs {
+				_req := *req
+				_req.UHostId = sdk.String(base.PickResourceID(id))
+				_, err := base.BizClient.LeaveIsolationGroup(&_req)
+				if err != nil {
+					base.HandleError(err)
+					continue
+				}
+				fmt.Fprintf(out, "uhost[%s] left isolation group[%s]\n", *_req.UHostId, *req.GroupId)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	req.GroupId = flags.String("isolation-group-id", "", "Required. Resource ID of isolation group")
+	flags.StringSliceVar(&uhostIDs, "uhost-id", nil, "Required. Resource ID of uhost instances to remove from the isolation group")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	flags.SetFlagValuesFunc("isolation-group-id", func() []string {
+		return getIsolationGroupList(*req.ProjectId, *req.Region)
+	})
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	cmd.MarkFlagRequired("isolation-group-id")
+	cmd.MarkFlagRequired("uhost-id")
+	return cmd
+}
+
+func getIsolationGroupList(project, region string) []string {
+	req := base.BizClient.NewDescribeIsolationGroupRequest()
+	req.ProjectId = sdk.String(project)
+	req.Region = sdk.String(region)
+	req.Limit = sdk.Int(100)
+	resp, err := base.BizClient.DescribeIsolationGroup(req)
+	if err != nil {
+		return nil
+	}
+	list := []string{}
+	for _, group := range resp.IsolationGroupSet {
+		list = append(list, group.GroupId+"/"+strings.Replace(group.GroupName, " ", "-", -1))
+	}
+	return list
+}
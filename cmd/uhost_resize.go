@@ -0,0 +1,273 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//UHostResizeRow 调整配置计划表格行
+type UHostResizeRow struct {
+	ResourceID string
+	Name       string
+	State      string
+	CPU        string
+	Memory     string
+	SystemDisk string
+	DataDisk   string
+	PriceDiff  string
+}
+
+//uhostResizePlan 一台主机的调整计划
+type uhostResizePlan struct {
+	request.CommonBase
+	inst      *uhost.UHostInstanceSet
+	resizeReq *uhost.ResizeUHostInstanceRequest
+	diskReqs  []*uhost.ResizeAttachedDiskRequest
+	row       UHostResizeRow
+	restart   bool
+	async     bool
+}
+
+//validateUHostSpec 校验调整后的CPU和内存, 与机型相关的限制由接口校验
+func validateUHostSpec(cpu, memoryGB int) error {
+	if cpu < 1 {
+		return fmt.Errorf("cpu should be greater than 0, got %d", cpu)
+	}
+	if memoryGB < 1 {
+		return fmt.Errorf("memory-gb should be greater than 0, got %d", memoryGB)
+	}
+	return nil
+}
+
+//newUHostResizePlan 以uhost resize的参数为模板生成一台主机的调整计划并查询差价
+func newUHostResizePlan(tmpl *uhost.ResizeUHostInstanceRequest, uhostID, dataDiskID string) (*uhostResizePlan, error) {
+	host, err := describeUHostByID(uhostID, *tmpl.ProjectId, *tmpl.Region, *tmpl.Zone)
+	if err != nil {
+		return nil, err
+	}
+	if host == nil {
+		return nil, fmt.Errorf("uhost[%s] does not exist", uhostID)
+	}
+	inst := host.(*uhost.UHostInstanceSet)
+	plan := &uhostResizePlan{
+		inst: inst,
+		row: UHostResizeRow{
+			ResourceID: inst.UHostId,
+			Name:       inst.Name,
+			State:      inst.State,
+			CPU:        fmt.Sprintf("%d", inst.CPU),
+			Memory:     fmt.Sprintf("%dGB", inst.Memory/1024),
+		},
+	}
+
+	req := base.BizClient.NewResizeUHostInstanceRequest()
+	req.ProjectId = tmpl.ProjectId
+	req.Region = tmpl.Region
+	req.Zone = tmpl.Zone
+	req.UHostId = sdk.String(inst.UHostId)
+	req.NetCapValue = tmpl.NetCapValue
+	priceReq := base.BizClient.NewGetUHostUpgradePriceRequest()
+	priceReq.ProjectId = req.ProjectId
+	priceReq.Region = req.Region
+	priceReq.Zone = req.Zone
+	priceReq.UHostId = req.UHostId
+	priceReq.NetCapValue = req.NetCapValue
+
+	cpu, memory := inst.CPU, inst.Memory
+	if tmpl.CPU != nil {
+		cpu = *tmpl.CPU
+	}
+	if tmpl.Memory != nil {
+		memory = *tmpl.Memory
+	}
+	if tmpl.CPU != nil || tmpl.Memory != nil {
+		if err := validateUHostSpec(cpu, memory/1024); err != nil {
+			return nil, fmt.Errorf("uhost[%s]: %v", inst.UHostId, err)
+		}
+	}
+	if cpu != inst.CPU {
+		req.CPU = sdk.Int(cpu)
+		priceReq.CPU = req.CPU
+		plan.row.CPU = fmt.Sprintf("%d -> %d", inst.CPU, cpu)
+	}
+	if memory != inst.Memory {
+		req.Memory = sdk.Int(memory)
+		priceReq.Memory = req.Memory
+		plan.row.Memory = fmt.Sprintf("%dGB -> %dGB", inst.Memory/1024, memory/1024)
+	}
+
+	var bootDisk *uhost.UHostDiskSet
+	dataDisks := []uhost.UHostDiskSet{}
+	for idx, disk := range inst.DiskSet {
+		if disk.IsBoot == "True" {
+			bootDisk = &inst.DiskSet[idx]
+		} else {
+			dataDisks = append(dataDisks, disk)
+		}
+	}
+	if tmpl.BootDiskSpace != nil {
+		if bootDisk == nil {
+			return nil, fmt.Errorf("system disk of uhost[%s] not found", inst.UHostId)
+		}
+		diskReq, err := plan.resizeDisk(req, *bootDisk, *tmpl.BootDiskSpace)
+		if err != nil {
+			return nil, err
+		}
+		if diskReq == nil && req.BootDiskSpace == nil {
+			plan.row.SystemDisk = fmt.Sprintf("%dGB", bootDisk.Size)
+		} else {
+			priceReq.BootDiskSpace = tmpl.BootDiskSpace
+			plan.row.SystemDisk = fmt.Sprintf("%dGB -> %dGB", bootDisk.Size, *tmpl.BootDiskSpace)
+		}
+	}
+	if tmpl.DiskSpace != nil {
+		disk, err := pickResizeDataDisk(inst.UHostId, dataDisks, dataDiskID)
+		if err != nil {
+			return nil, err
+		}
+		diskReq, err := plan.resizeDisk(req, disk, *tmpl.DiskSpace)
+		if err != nil {
+			return nil, err
+		}
+		if diskReq == nil && req.DiskSpace == nil {
+			plan.row.DataDisk = fmt.Sprintf("%s %dGB", disk.DiskId, disk.Size)
+		} else {
+			priceReq.DiskSpace = tmpl.DiskSpace
+			plan.row.DataDisk = fmt.Sprintf("%s %dGB -> %dGB", disk.DiskId, disk.Size, *tmpl.DiskSpace)
+		}
+	}
+
+	if req.CPU != nil || req.Memory != nil || req.NetCapValue != nil || req.DiskSpace != nil || req.BootDiskSpace != nil {
+		plan.resizeReq = req
+	}
+	if plan.unchanged() {
+		plan.row.PriceDiff = "-"
+		return plan, nil
+	}
+	resp, err := base.BizClient.GetUHostUpgradePrice(priceReq)
+	if err != nil {
+		base.LogError(fmt.Sprintf("get upgrade price of uhost[%s] failed: %s", inst.UHostId, base.ParseError(err)))
+		plan.row.PriceDiff = "unknown"
+	} else {
+		plan.row.PriceDiff = fmt.Sprintf("%.2f", resp.Price)
+	}
+	return plan, nil
+}
+
+//resizeDisk 本地盘通过ResizeUHostInstance调整, 云盘通过ResizeAttachedDisk调整. 大小不变时返回nil
+func (p *uhostResizePlan) resizeDisk(req *uhost.ResizeUHostInstanceRequest, disk uhost.UHostDiskSet, sizeGB int) (*uhost.ResizeAttachedDiskRequest, error) {
+	if sizeGB < disk.Size {
+		return nil, fmt.Errorf("disk[%s] of uhost[%s] is %dGB, it does not support shrinkage to %dGB", disk.DiskId, p.inst.UHostId, disk.Size, sizeGB)
+	}
+	if sizeGB%10 != 0 {
+		return nil, fmt.Errorf("disk size should be a multiple of 10, got %d", sizeGB)
+	}
+	if sizeGB == disk.Size {
+		return nil, nil
+	}
+	if strings.HasPrefix(disk.DiskType, "LOCAL") {
+		if disk.IsBoot == "True" {
+			req.BootDiskSpace = sdk.Int(sizeGB)
+		} else {
+			req.DiskSpace = sdk.Int(sizeGB)
+		}
+		return nil, nil
+	}
+	diskReq := base.BizClient.NewResizeAttachedDiskRequest()
+	diskReq.ProjectId = req.ProjectId
+	diskReq.Region = req.Region
+	diskReq.Zone = req.Zone
+	diskReq.UHostId = req.UHostId
+	diskReq.DiskId = sdk.String(disk.DiskId)
+	diskReq.DiskSpace = sdk.Int(sizeGB)
+	p.diskReqs = append(p.diskReqs, diskReq)
+	return diskReq, nil
+}
+
+func (p *uhostResizePlan) unchanged() bool {
+	return p.resizeReq == nil && len(p.diskReqs) == 0
+}
+
+//pickResizeDataDisk 主机只有一块数据盘时无需指定磁盘ID
+func pickResizeDataDisk(uhostID string, disks []uhost.UHostDiskSet, diskID string) (uhost.UHostDiskSet, error) {
+	if diskID != "" {
+		for _, disk := range disks {
+			if disk.DiskId == diskID {
+				return disk, nil
+			}
+		}
+		return uhost.UHostDiskSet{}, fmt.Errorf("data disk[%s] is not attached to uhost[%s]", diskID, uhostID)
+	}
+	if len(disks) == 0 {
+		return uhost.UHostDiskSet{}, fmt.Errorf("uhost[%s] has no data disk", uhostID)
+	}
+	if len(disks) > 1 {
+		return uhost.UHostDiskSet{}, fmt.Errorf("uhost[%s] has %d data disks, please assign data-disk-id", uhostID, len(disks))
+	}
+	return disks[0], nil
+}
+
+//resizeUHostByPlan 可并发调用, 按计划调整主机配置后再扩容云盘, restart为true时恢复主机原来的运行状态
+func resizeUHostByPlan(creq request.Common) (bool, []string) {
+	plan := creq.(*uhostResizePlan)
+	id := plan.inst.UHostId
+	if plan.unchanged() {
+		text := fmt.Sprintf("uhost[%s] unchanged", id)
+		block := ux.NewBlock()
+		ux.Doc.Append(block)
+		block.Append(text)
+		return true, []string{text}
+	}
+	logs := []string{}
+	if plan.resizeReq != nil {
+		var success bool
+		if plan.restart {
+			success, logs = resizeAndStartUHost(plan.resizeReq)
+		} else {
+			//云盘需要在主机调整完成后才能扩容, 此时即使指定了async也要等待
+			success, logs = resizeUHost(plan.resizeReq, plan.async && len(plan.diskReqs) == 0)
+		}
+		if !success {
+			return false, logs
+		}
+	}
+	if len(plan.diskReqs) == 0 {
+		return true, logs
+	}
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	for _, diskReq := range plan.diskReqs {
+		logs = append(logs, fmt.Sprintf("api:ResizeAttachedDisk, request:%v", base.ToQueryMap(diskReq)))
+		_, err := base.BizClient.ResizeAttachedDisk(diskReq)
+		if err != nil {
+			text := fmt.Sprintf("resize disk[%s] of uhost[%s] failed: %s", *diskReq.DiskId, id, base.ParseError(err))
+			block.Append(text)
+			return false, append(logs, text)
+		}
+		text := fmt.Sprintf("disk[%s] of uhost[%s] resized to %dGB", *diskReq.DiskId, id, *diskReq.DiskSpace)
+		block.Append(text)
+		logs = append(logs, text)
+	}
+	return true, logs
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+)
+
+type validateUHostSpecTest struct {
+	cpu         int
+	memoryGB    int
+	expectedErr bool
+}
+
+func (test *validateUHostSpecTest) run(t *testing.T) {
+	err := validateUHostSpec(test.cpu, test.memoryGB)
+	if test.expectedErr && err == nil {
+		t.Errorf("validateUHostSpec(%d, %d), expected error, got nil", test.cpu, test.memoryGB)
+	}
+	if !test.expectedErr && err != nil {
+		t.Errorf("validateUHostSpec(%d, %d), unexpected error: %v", test.cpu, test.memoryGB, err)
+	}
+}
+
+func TestValidateUHostSpec(t *testing.T) {
+	tests := []validateUHostSpecTest{
+		{cpu: 1, memoryGB: 1},
+		{cpu: 3, memoryGB: 100},
+		{cpu: 0, memoryGB: 4, expectedErr: true},
+		{cpu: 2, memoryGB: 0, expectedErr: true},
+		{cpu: -2, memoryGB: 4, expectedErr: true},
+		{cpu: 2, memoryGB: -4, expectedErr: true},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//rollingOption --rolling --batch-size --pause
+type rollingOption struct {
+	enabled   bool
+	batchSize int
+	pause     time.Duration
+}
+
+func bindRolling(flags *pflag.FlagSet) *rollingOption {
+	o := &rollingOption{}
+	flags.BoolVar(&o.enabled, "rolling", false, "Optional. Operate uhost instances batch by batch. Each uhost is disabled in the ULB vservers it belongs to before the operation, and enabled again after it is running. The rollout is aborted if any uhost of a batch fails")
+	flags.IntVar(&o.batchSize, "batch-size", 1, "Optional. The number of uhost instances operated at the same time. It takes effect when rolling is assigned")
+	flags.DurationVar(&o.pause, "pause", 0, "Optional. Time to wait between batches, such as 30s, 1m. It takes effect when rolling is assigned")
+	flags.SetFlagValues("rolling", "true", "false")
+	return o
+}
+
+//ulbBackend 主机在ULB VServer中的后端节点
+type ulbBackend struct {
+	ulbID     string
+	vserverID string
+	backendID string
+	enabled   int
+}
+
+//rollingItem 滚动操作中的一台主机
+type rollingItem struct {
+	request.CommonBase
+	uhostID  string
+	backends []ulbBackend
+	restore  bool
+	action   func(uhostID string) (bool, []string)
+}
+
+//run 按批次执行action, 某一批次有失败时中止后续批次. restore为false时操作完成后不再启用ULB后端节点, 如关机
+func (o *rollingOption) run(uhostIDs []string, project, region string, restore bool, action func(uhostID string) (bool, []string)) error {
+	if o.batchSize < 1 {
+		return fmt.Errorf("batch-size should be greater than 0, got %d", o.batchSize)
+	}
+	if o.pause < 0 {
+		return fmt.Errorf("pause should not be negative, got %s", o.pause)
+	}
+	backends, err := getUHostBackends(project, region)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(uhostIDs))
+	for idx, id := range uhostIDs {
+		ids[idx] = base.PickResourceID(id)
+	}
+
+	batchCount := (len(ids) + o.batchSize - 1) / o.batchSize
+	for batch := 0; batch < batchCount; batch++ {
+		start, end := batch*o.batchSize, (batch+1)*o.batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		block := ux.NewBlock()
+		ux.Doc.Append(block)
+		block.Append(fmt.Sprintf("batch %d/%d: %s", batch+1, batchCount, strings.Join(ids[start:end], ",")))
+
+		reqs := []request.Common{}
+		for _, id := range ids[start:end] {
+			item := &rollingItem{
+				uhostID:  id,
+				backends: backends[id],
+				restore:  restore,
+				action:   action,
+			}
+			item.SetProjectId(project)
+			item.SetRegion(region)
+			reqs = append(reqs, item)
+		}
+		coAction := newConcurrentAction(reqs, rollingAction)
+		coAction.setParallel(o.batchSize)
+		results := coAction.Do()
+		failed := []string{}
+		for idx, result := range results {
+			if !result.Success {
+				failed = append(failed, ids[start+idx])
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("batch %d/%d failed on uhost[%s], rollout aborted. uhost not operated: [%s]", batch+1, batchCount, strings.Join(failed, ","), strings.Join(ids[end:], ","))
+		}
+		if end < len(ids) && o.pause > 0 {
+			block.Append(fmt.Sprintf("batch %d/%d done, pausing %s", batch+1, batchCount, o.pause))
+			time.Sleep(o.pause)
+		}
+	}
+	return nil
+}
+
+//rollingAction 禁用主机的ULB后端节点, 执行操作, 成功后再启用
+func rollingAction(creq request.Common) (bool, []string) {
+	item := creq.(*rollingItem)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{}
+	drained := []ulbBackend{}
+	for _, backend := range item.backends {
+		if backend.enabled == 0 {
+			continue
+		}
+		_logs, err := updateBackendEnabled(item, backend, 0)
+		logs = append(logs, _logs...)
+		if err != nil {
+			text := fmt.Sprintf("disable backend[%s] of ulb[%s] failed: %s", backend.backendID, backend.ulbID, base.ParseError(err))
+			block.Append(text)
+			logs = append(logs, text)
+			_logs, _ = restoreBackends(item, drained, block)
+			return false, append(logs, _logs...)
+		}
+		drained = append(drained, backend)
+		text := fmt.Sprintf("uhost[%s] disabled in ulb[%s] vserver[%s]", item.uhostID, backend.ulbID, backend.vserverID)
+		block.Append(text)
+		logs = append(logs, text)
+	}
+
+	success, _logs := item.action(item.uhostID)
+	logs = append(logs, _logs...)
+	if !success {
+		if len(drained) > 0 {
+			text := fmt.Sprintf("backends of uhost[%s] are left disabled, enable them by 'ucloud ulb vserver backend update' after fixing it", item.uhostID)
+			block.Append(text)
+			logs = append(logs, text)
+		}
+		return false, logs
+	}
+	if !item.restore {
+		return true, logs
+	}
+	_logs, success = restoreBackends(item, drained, block)
+	return success, append(logs, _logs...)
+}
+
+//restoreBackends 重新启用后端节点, 全部成功时返回true
+func restoreBackends(item *rollingItem, backends []ulbBackend, block *ux.Block) ([]string, bool) {
+	logs := []string{}
+	success := true
+	for _, backend := range backends {
+		_, err := updateBackendEnabled(item, backend, 1)
+		if err != nil {
+			text := fmt.Sprintf("enable backend[%s] of ulb[%s] failed: %s", backend.backendID, backend.ulbID, base.ParseError(err))
+			block.Append(text)
+			logs = append(logs, text)
+			success = false
+			continue
+		}
+		text := fmt.Sprintf("uhost[%s] enabled in ulb[%s] vserver[%s]", item.uhostID, backend.ulbID, backend.vserverID)
+		block.Append(text)
+		logs = append(logs, text)
+	}
+	return logs, success
+}
+
+func updateBackendEnabled(item *rollingItem, backend ulbBackend, enabled int) ([]string, error) {
+	req := base.BizClient.NewUpdateBackendAttributeRequest()
+	req.ProjectId = sdk.String(item.GetProjectId())
+	req.Region = sdk.String(item.GetRegion())
+	req.ULBId = sdk.String(backend.ulbID)
+	req.BackendId = sdk.String(backend.backendID)
+	req.Enabled = sdk.Int(enabled)
+	logs := []string{fmt.Sprintf("api:UpdateBackendAttribute, request:%v", base.ToQueryMap(req))}
+	_, err := base.BizClient.UpdateBackendAttribute(req)
+	return logs, err
+}
+
+//getUHostBackends 地域内所有ULB的后端节点, 按主机资源ID分组
+func getUHostBackends(project, region string) (map[string][]ulbBackend, error) {
+	ulbs, err := getAllULB(project, region)
+	if err != nil {
+		return nil, err
+	}
+	backends := make(map[string][]ulbBackend)
+	for _, ulb := range ulbs {
+		for _, vserver := range ulb.VServerSet {
+			for _, backend := range vserver.BackendSet {
+				backends[backend.ResourceId] = append(backends[backend.ResourceId], ulbBackend{
+					ulbID:     ulb.ULBId,
+					vserverID: vserver.VServerId,
+					backendID: backend.BackendId,
+					enabled:   backend.Enabled,
+				})
+			}
+		}
+	}
+	return backends, nil
+}
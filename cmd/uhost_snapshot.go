@@ -0,0 +1,546 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-sdk-go/services/udisk"
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//snapshotGroupCommentPrefix 快照组的快照备注前缀, 完整备注为 snapshot-group:<label>:<快照数量>, 用于查找同一组的快照
+const snapshotGroupCommentPrefix = "snapshot-group:"
+
+//snapshotGroupComment 快照组的备注, 记录组内快照数量以便恢复时检查快照组是否完整
+func snapshotGroupComment(label string, count int) string {
+	return fmt.Sprintf("%s%s:%d", snapshotGroupCommentPrefix, label, count)
+}
+
+//parseSnapshotGroupComment 从备注中解析快照组的标签和快照数量
+func parseSnapshotGroupComment(comment string) (string, int, bool) {
+	if !strings.HasPrefix(comment, snapshotGroupCommentPrefix) {
+		return "", 0, false
+	}
+	text := strings.TrimPrefix(comment, snapshotGroupCommentPrefix)
+	idx := strings.LastIndex(text, ":")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	count, err := strconv.Atoi(text[idx+1:])
+	if err != nil || count <= 0 {
+		return "", 0, false
+	}
+	return text[:idx], count, true
+}
+
+//snapshotGroupItem 快照组中的一个云硬盘
+type snapshotGroupItem struct {
+	request.CommonBase
+	req        *udisk.CreateUDiskSnapshotRequest
+	snapshotID string
+}
+
+//SnapshotGroupRow 快照组表格行
+type SnapshotGroupRow struct {
+	SnapshotID string
+	Name       string
+	UDisk      string
+	UHost      string
+	Size       string
+	State      string
+}
+
+//NewCmdUHostSnapshot ucloud uhost snapshot
+func NewCmdUHostSnapshot(out io.Writer) *cobra.Command {
+	var uhostID, project, region, zone, label, preHook, postHook string
+	var stop, async bool
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Snapshot all cloud disks of a uhost instance at the same time",
+		Long: `Snapshot all cloud disks of a uhost instance at the same time, the snapshots share a group label.
+To get consistent snapshots, stop the uhost by --stop, or freeze file systems by --pre-hook and unfreeze them by --post-hook.
+Hooks are executed by 'sh -c' locally, with environment variables UHOST_ID, UHOST_IP and SNAPSHOT_GROUP. Post hook is executed whenever pre hook succeeds.
+Local disks are skipped`,
+		Example: `ucloud uhost snapshot --uhost-id uhost-xxx --pre-hook 'ssh root@$UHOST_IP fsfreeze -f /data' --post-hook 'ssh root@$UHOST_IP fsfreeze -u /data'`,
+		Run: func(c *cobra.Command, args []string) {
+			uhostID = base.PickResourceID(uhostID)
+			host, err := describeUHostByID(uhostID, project, region, zone)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if host == nil {
+				base.Cxt.Printf("Error, uhost[%s] does not exist\n", uhostID)
+				return
+			}
+			inst := host.(*uhost.UHostInstanceSet)
+			if label == "" {
+				label = fmt.Sprintf("%s-%s", inst.UHostId, time.Now().Format("20060102150405"))
+			}
+			items := []*snapshotGroupItem{}
+			reqs := []request.Common{}
+			for _, disk := range inst.DiskSet {
+				if strings.HasPrefix(disk.DiskType, "LOCAL") {
+					base.Cxt.Printf("local disk[%s] of uhost[%s] skipped\n", disk.DiskId, inst.UHostId)
+					continue
+				}
+				req := base.BizClient.NewCreateUDiskSnapshotRequest()
+				req.ProjectId = sdk.String(project)
+				req.Region = sdk.String(region)
+				req.Zone = sdk.String(inst.Zone)
+				req.UDiskId = sdk.String(disk.DiskId)
+				req.Name = sdk.String(fmt.Sprintf("%s-%s", label, disk.DiskId))
+				item := &snapshotGroupItem{req: req}
+				items = append(items, item)
+				reqs = append(reqs, item)
+			}
+			if len(items) == 0 {
+				base.Cxt.Printf("Error, uhost[%s] has no cloud disk to snapshot\n", inst.UHostId)
+				return
+			}
+			for _, item := range items {
+				item.req.Comment = sdk.String(snapshotGroupComment(label, len(items)))
+			}
+
+			stopped := false
+			if stop && inst.State == status.HOST_RUNNING {
+				req := base.BizClient.NewStopUHostInstanceRequest()
+				req.ProjectId = sdk.String(project)
+				req.Region = sdk.String(region)
+				req.Zone = sdk.String(inst.Zone)
+				req.UHostId = sdk.String(inst.UHostId)
+				success, _ := stopUHost(req, false)
+				if !success {
+					return
+				}
+				stopped = true
+			}
+			env := append(os.Environ(), "UHOST_ID="+inst.UHostId, "UHOST_IP="+uhostPrivateIP(inst), "SNAPSHOT_GROUP="+label)
+			frozen := runSnapshotHook("pre hook", preHook, env)
+			created := false
+			if frozen {
+				coAction := newConcurrentAction(reqs, createGroupSnapshot)
+				created = allSucceeded(coAction.Do())
+				runSnapshotHook("post hook", postHook, env)
+			}
+			if stopped {
+				req := base.BizClient.NewStartUHostInstanceRequest()
+				req.ProjectId = sdk.String(project)
+				req.Region = sdk.String(region)
+				req.Zone = sdk.String(inst.Zone)
+				req.UHostId = sdk.String(inst.UHostId)
+				startUHost(req, false)
+			}
+			if !frozen {
+				return
+			}
+			if !created {
+				base.Cxt.Printf("Error, snapshot group[%s] is incomplete, deleting the snapshots created\n", label)
+				deleteGroupSnapshots(items)
+				return
+			}
+			if !async {
+				coAction := newConcurrentAction(reqs, waitGroupSnapshot)
+				if !allSucceeded(coAction.Do()) {
+					return
+				}
+			}
+			ids := []string{}
+			for _, item := range items {
+				ids = append(ids, item.snapshotID)
+			}
+			fmt.Fprintf(out, "snapshot group[%s] created: %s\n", label, strings.Join(ids, ","))
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&uhostID, "uhost-id", "", "Required. Resource ID of the uhost to snapshot")
+	flags.StringVar(&label, "group-label", "", "Optional. Label shared by the snapshots. '<uhost-id>-<time>' by default")
+	flags.BoolVar(&stop, "stop", false, "Optional. Stop the uhost before snapshotting and start it after")
+	flags.StringVar(&preHook, "pre-hook", "", "Optional. Command executed before snapshotting, such as freezing file systems. Snapshotting is aborted if it fails")
+	flags.StringVar(&postHook, "post-hook", "", "Optional. Command executed after snapshotting, such as unfreezing file systems")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", "", "Optional. Assign availability zone")
+	flags.BoolVarP(&async, "async", "a", false, "Optional. Do not wait for the snapshots to be available.")
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, project, region, zone)
+	})
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	flags.SetFlagValues("stop", "true", "false")
+	cmd.MarkFlagRequired("uhost-id")
+	return cmd
+}
+
+//NewCmdUHostRestoreSnapshotGroup ucloud uhost restore-snapshot-group
+func NewCmdUHostRestoreSnapshotGroup(out io.Writer) *cobra.Command {
+	var project, region, zone, label string
+	var clone, yes, async bool
+	cmd := &cobra.Command{
+		Use:   "restore-snapshot-group",
+		Short: "Restore or clone udisks from a snapshot group",
+		Long: `Restore or clone udisks from a snapshot group created by 'ucloud uhost snapshot'.
+Restoring overwrites the udisks, the uhost they are attached to is stopped before restoring and started after.
+Cloning creates new udisks from the snapshots and leaves the original udisks untouched`,
+		Example: "ucloud uhost restore-snapshot-group --group-label uhost-xxx-20190102150405 --clone",
+		Run: func(c *cobra.Command, args []string) {
+			snapshots, err := getUDiskSnapshots("", project, region, zone)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			group := []udisk.UDiskSnapshotSet{}
+			rows := []SnapshotGroupRow{}
+			expected := 0
+			for _, s := range snapshots {
+				groupLabel, count, ok := parseSnapshotGroupComment(s.Comment)
+				if !ok || groupLabel != label {
+					continue
+				}
+				expected = count
+				group = append(group, s)
+				rows = append(rows, SnapshotGroupRow{
+					SnapshotID: s.SnapshotId,
+					Name:       s.Name,
+					UDisk:      s.UDiskId,
+					UHost:      s.UHostId,
+					Size:       fmt.Sprintf("%dGB", s.Size),
+					State:      s.Status,
+				})
+			}
+			if len(group) == 0 {
+				base.Cxt.Printf("Error, snapshot group[%s] not found\n", label)
+				return
+			}
+			base.PrintList(rows, out)
+			if len(group) != expected {
+				base.Cxt.Printf("Error, snapshot group[%s] is incomplete, expected %d snapshots, found %d\n", label, expected, len(group))
+				return
+			}
+			for _, s := range group {
+				if s.Status != status.SNAPSHOT_NORMAL {
+					base.Cxt.Printf("Error, snapshot[%s] is %s, expected %s\n", s.SnapshotId, s.Status, status.SNAPSHOT_NORMAL)
+					return
+				}
+			}
+			if !yes {
+				text := "Do you want to restore the udisks? Data written after the snapshots will be lost"
+				if clone {
+					text = "Do you want to clone udisks from the snapshots?"
+				}
+				sure, err := ux.Prompt(text)
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+			if clone {
+				cloneSnapshotGroup(group, label, project, region, zone, async)
+			} else {
+				restoreSnapshotGroup(group, project, region)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&label, "group-label", "", "Required. Label of the snapshot group")
+	flags.BoolVar(&clone, "clone", false, "Optional. Clone new udisks from the snapshots instead of restoring the original udisks")
+	bindProjectIDS(&project, flags)
+	bindRegionS(&region, flags)
+	flags.StringVar(&zone, "zone", "", "Optional. Assign availability zone")
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation.")
+	flags.BoolVarP(&async, "async", "a", false, "Optional. Do not wait for the cloned udisks to be available. It takes effect when clone is assigned")
+	flags.SetFlagValues("clone", "true", "false")
+	flags.SetFlagValuesFunc("zone", func() []string {
+		return getZoneList(region)
+	})
+	cmd.MarkFlagRequired("group-label")
+	return cmd
+}
+
+//cloneSnapshotGroup 从快照组的每个快照并发克隆云硬盘
+func cloneSnapshotGroup(group []udisk.UDiskSnapshotSet, label, project, region, zone string, async bool) {
+	reqs := []request.Common{}
+	for _, s := range group {
+		req := base.BizClient.NewCloneUDiskSnapshotRequest()
+		req.ProjectId = sdk.String(project)
+		req.Region = sdk.String(region)
+		req.Zone = sdk.String(zone)
+		disk, err := describeUDiskIns(s.UDiskId, project, region, zone)
+		if err == nil && disk != nil {
+			req.Zone = sdk.String(disk.Zone)
+		}
+		req.SourceId = sdk.String(s.SnapshotId)
+		req.Name = sdk.String(fmt.Sprintf("%s-%s", s.UDiskName, label))
+		req.Size = sdk.Int(s.Size)
+		req.Comment = sdk.String(snapshotGroupComment(label, len(group)))
+		reqs = append(reqs, req)
+	}
+	coAction := newConcurrentAction(reqs, func(creq request.Common) (bool, []string) {
+		return cloneUDiskFromSnapshot(creq.(*udisk.CloneUDiskSnapshotRequest), async)
+	})
+	coAction.Do()
+}
+
+//restoreSnapshotGroup 关闭云硬盘挂载的主机, 并发恢复云硬盘后再启动主机
+func restoreSnapshotGroup(group []udisk.UDiskSnapshotSet, project, region string) {
+	reqs := []request.Common{}
+	uhostIDs := make(map[string]bool)
+	stoppedZones := make(map[string]string)
+	for _, s := range group {
+		disk, err := describeUDiskIns(s.UDiskId, project, region, "")
+		if err != nil {
+			base.HandleError(err)
+			return
+		}
+		if disk == nil {
+			base.Cxt.Printf("Error, udisk[%s] of snapshot[%s] does not exist, clone it by --clone\n", s.UDiskId, s.SnapshotId)
+			return
+		}
+		req := base.BizClient.NewRestoreUDiskRequest()
+		req.ProjectId = sdk.String(project)
+		req.Region = sdk.String(region)
+		req.Zone = sdk.String(disk.Zone)
+		req.UDiskId = sdk.String(disk.UDiskId)
+		req.SnapshotId = sdk.String(s.SnapshotId)
+		reqs = append(reqs, req)
+		if disk.UHostId != "" {
+			uhostIDs[disk.UHostId] = true
+		}
+	}
+
+	for id := range uhostIDs {
+		host, err := describeUHostByID(id, project, region, "")
+		if err != nil {
+			base.HandleError(err)
+			return
+		}
+		if host == nil || host.(*uhost.UHostInstanceSet).State != status.HOST_RUNNING {
+			continue
+		}
+		inst := host.(*uhost.UHostInstanceSet)
+		req := base.BizClient.NewStopUHostInstanceRequest()
+		req.ProjectId = sdk.String(project)
+		req.Region = sdk.String(region)
+		req.Zone = sdk.String(inst.Zone)
+		req.UHostId = sdk.String(id)
+		success, _ := stopUHost(req, false)
+		if !success {
+			startStoppedUHosts(stoppedZones, project, region)
+			return
+		}
+		stoppedZones[id] = inst.Zone
+	}
+	coAction := newConcurrentAction(reqs, restoreUDisk)
+	if allSucceeded(coAction.Do()) {
+		startStoppedUHosts(stoppedZones, project, region)
+		return
+	}
+	for id := range stoppedZones {
+		base.Cxt.Printf("uhost[%s] is left stopped, start it by 'ucloud uhost start' after checking its udisks\n", id)
+	}
+}
+
+func startStoppedUHosts(zones map[string]string, project, region string) {
+	for id, zone := range zones {
+		req := base.BizClient.NewStartUHostInstanceRequest()
+		req.ProjectId = sdk.String(project)
+		req.Region = sdk.String(region)
+		req.Zone = sdk.String(zone)
+		req.UHostId = sdk.String(id)
+		startUHost(req, false)
+	}
+}
+
+//createGroupSnapshot 可并发调用, 只创建快照, 不等待快照可用以缩短冻结文件系统的时间
+func createGroupSnapshot(creq request.Common) (bool, []string) {
+	item := creq.(*snapshotGroupItem)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:CreateUDiskSnapshot, request:%v", base.ToQueryMap(item.req))}
+	resp, err := base.BizClient.CreateUDiskSnapshot(item.req)
+	if err != nil {
+		text := fmt.Sprintf("snapshot udisk[%s] failed: %s", *item.req.UDiskId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if len(resp.SnapshotId) != 1 {
+		text := fmt.Sprintf("snapshot udisk[%s] failed: expect snapshot count 1, accept %d", *item.req.UDiskId, len(resp.SnapshotId))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	item.snapshotID = resp.SnapshotId[0]
+	text := fmt.Sprintf("snapshot[%s] of udisk[%s] is creating", item.snapshotID, *item.req.UDiskId)
+	block.Append(text)
+	return true, append(logs, text)
+}
+
+//deleteGroupSnapshots 删除不完整的快照组中已创建的快照, 避免被当作完整的快照组恢复. 创建中的快照无法删除, 先等待其可用
+func deleteGroupSnapshots(items []*snapshotGroupItem) {
+	for _, item := range items {
+		if item.snapshotID == "" {
+			continue
+		}
+		block := ux.NewBlock()
+		ux.Doc.Append(block)
+		text := fmt.Sprintf("waiting snapshot[%s] of udisk[%s] to delete it", item.snapshotID, *item.req.UDiskId)
+		err := waitUDiskSnapshotState(item.snapshotID, *item.req.ProjectId, *item.req.Region, *item.req.Zone, text, block)
+		if err != nil {
+			block.Append(fmt.Sprintf("%s, delete snapshot[%s] manually", err.Error(), item.snapshotID))
+			continue
+		}
+		req := base.BizClient.NewDeleteUDiskSnapshotRequest()
+		req.ProjectId = item.req.ProjectId
+		req.Region = item.req.Region
+		req.Zone = item.req.Zone
+		req.UDiskId = item.req.UDiskId
+		req.SnapshotId = sdk.String(item.snapshotID)
+		_, err = base.BizClient.DeleteUDiskSnapshot(req)
+		if err != nil {
+			block.Append(fmt.Sprintf("delete snapshot[%s] failed: %s", item.snapshotID, base.ParseError(err)))
+			continue
+		}
+		block.Append(fmt.Sprintf("snapshot[%s] deleted", item.snapshotID))
+	}
+}
+
+//waitGroupSnapshot 可并发调用, 等待快照可用
+func waitGroupSnapshot(creq request.Common) (bool, []string) {
+	item := creq.(*snapshotGroupItem)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	req := item.req
+	text := fmt.Sprintf("waiting snapshot[%s] of udisk[%s]", item.snapshotID, *req.UDiskId)
+	err := waitUDiskSnapshotState(item.snapshotID, *req.ProjectId, *req.Region, *req.Zone, text, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, []string{err.Error()}
+	}
+	return true, []string{fmt.Sprintf("snapshot[%s] created", item.snapshotID)}
+}
+
+//cloneUDiskFromSnapshot 可并发调用, 从快照克隆云硬盘
+func cloneUDiskFromSnapshot(req *udisk.CloneUDiskSnapshotRequest, async bool) (bool, []string) {
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:CloneUDiskSnapshot, request:%v", base.ToQueryMap(req))}
+	resp, err := base.BizClient.CloneUDiskSnapshot(req)
+	if err != nil {
+		text := fmt.Sprintf("clone udisk from snapshot[%s] failed: %s", *req.SourceId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	if len(resp.UDiskId) != 1 {
+		text := fmt.Sprintf("clone udisk from snapshot[%s] failed: expect udisk count 1, accept %d", *req.SourceId, len(resp.UDiskId))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	udiskID := resp.UDiskId[0]
+	text := fmt.Sprintf("udisk[%s] is cloning from snapshot[%s]", udiskID, *req.SourceId)
+	logs = append(logs, text)
+	if async {
+		block.Append(text)
+		return true, logs
+	}
+	err = waitUDiskState(udiskID, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.DISK_AVAILABLE}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("udisk[%s] cloned from snapshot[%s]", udiskID, *req.SourceId))
+}
+
+//restoreUDisk 可并发调用, 从快照恢复云硬盘并等待恢复完成
+func restoreUDisk(creq request.Common) (bool, []string) {
+	req := creq.(*udisk.RestoreUDiskRequest)
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	logs := []string{fmt.Sprintf("api:RestoreUDisk, request:%v", base.ToQueryMap(req))}
+	_, err := base.BizClient.RestoreUDisk(req)
+	if err != nil {
+		text := fmt.Sprintf("restore udisk[%s] failed: %s", *req.UDiskId, base.ParseError(err))
+		block.Append(text)
+		return false, append(logs, text)
+	}
+	text := fmt.Sprintf("udisk[%s] is restoring from snapshot[%s]", *req.UDiskId, *req.SnapshotId)
+	logs = append(logs, text)
+	err = waitUDiskState(*req.UDiskId, *req.ProjectId, *req.Region, *req.Zone, text, []string{status.DISK_AVAILABLE, status.DISK_INUSE}, block)
+	if err != nil {
+		block.Append(err.Error())
+		return false, append(logs, err.Error())
+	}
+	return true, append(logs, fmt.Sprintf("udisk[%s] restored from snapshot[%s]", *req.UDiskId, *req.SnapshotId))
+}
+
+//runSnapshotHook 执行钩子命令并把输出显示出来, 命令为空时直接返回true
+func runSnapshotHook(name, command string, env []string) bool {
+	if command == "" {
+		return true
+	}
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	hook := exec.Command("sh", "-c", command)
+	hook.Env = env
+	output, err := hook.CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if text != "" {
+		block.Append(text)
+	}
+	if err != nil {
+		block.Append(fmt.Sprintf("%s failed: %v", name, err))
+		return false
+	}
+	block.Append(name + " done")
+	return true
+}
+
+//uhostPrivateIP 主机的第一个内网IP
+func uhostPrivateIP(inst *uhost.UHostInstanceSet) string {
+	for _, ip := range inst.IPSet {
+		if ip.Type == "Private" {
+			return ip.IP
+		}
+	}
+	return ""
+}
+
+func allSucceeded(results []actionResult) bool {
+	for _, result := range results {
+		if !result.Success {
+			return false
+		}
+	}
+	return true
+}
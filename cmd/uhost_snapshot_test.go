@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+)
+
+type parseSnapshotGroupCommentTest struct {
+	comment       string
+	expectedLabel string
+	expectedCount int
+	expectedOK    bool
+}
+
+func (test *parseSnapshotGroupCommentTest) run(t *testing.T) {
+	label, count, ok := parseSnapshotGroupComment(test.comment)
+	if ok != test.expectedOK || label != test.expectedLabel || count != test.expectedCount {
+		t.Errorf("parseSnapshotGroupComment(%q), expected %q %d %t, got %q %d %t", test.comment, test.expectedLabel, test.expectedCount, test.expectedOK, label, count, ok)
+	}
+}
+
+func TestParseSnapshotGroupComment(t *testing.T) {
+	tests := []parseSnapshotGroupCommentTest{
+		{comment: snapshotGroupComment("uhost-1-20190102150405", 3), expectedLabel: "uhost-1-20190102150405", expectedCount: 3, expectedOK: true},
+		{comment: snapshotGroupComment("db:primary", 2), expectedLabel: "db:primary", expectedCount: 2, expectedOK: true},
+		{comment: "snapshot-group:uhost-1-20190102150405"},
+		{comment: "snapshot-group::2"},
+		{comment: "snapshot-group:label:0"},
+		{comment: "created by ucloud udisk snapshot-policy"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -0,0 +1,643 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ucloud/ucloud-sdk-go/services/pathx"
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//sshOption 连接主机的ssh选项
+type sshOption struct {
+	user         string
+	ipType       string
+	port         int
+	identityFile string
+	proxyJump    string
+	options      []string
+}
+
+//sshTarget 解析后的ssh连接目标
+type sshTarget struct {
+	UHostID string
+	Name    string
+	Host    string
+	Port    int
+	User    string
+}
+
+func bindSSHOption(flags *pflag.FlagSet) *sshOption {
+	o := &sshOption{}
+	flags.StringVarP(&o.user, "user", "l", "", "Optional. User to log in as. 'ubuntu' for Ubuntu images and 'root' for others by default")
+	flags.StringVar(&o.ipType, "ip-type", "auto", "Optional. Which address to connect to. Accept values: auto, public, private, gssh. 'auto' prefers the GlobalSSH endpoint, then public IP, then private IP. It prefers private IP if proxy-jump is assigned")
+	flags.IntVar(&o.port, "port", 22, "Optional. Port of sshd on the uhost")
+	flags.StringVarP(&o.identityFile, "identity-file", "i", "", "Optional. Path of private key file")
+	flags.StringVarP(&o.proxyJump, "proxy-jump", "J", "", "Optional. Bastion host to jump through, in the format of [user@]host[:port]")
+	flags.StringArrayVarP(&o.options, "ssh-option", "o", nil, "Optional. Options passed to ssh, such as StrictHostKeyChecking=no. Repeat it to pass multiple options")
+	flags.SetFlagValues("ip-type", "auto", "public", "private", "gssh")
+	flags.SetFlagValuesFunc("identity-file", func() []string {
+		return base.GetFileList("")
+	})
+	return o
+}
+
+//needGssh 是否需要查询GlobalSSH实例
+func (o *sshOption) needGssh() bool {
+	return o.ipType == "gssh" || o.ipType == "auto" && o.proxyJump == ""
+}
+
+//target 按照ip-type选择主机的连接地址
+func (o *sshOption) target(ins *uhost.UHostInstanceSet, gsshs []pathx.GlobalSSHInfo) (*sshTarget, error) {
+	t := &sshTarget{
+		UHostID: ins.UHostId,
+		Name:    ins.Name,
+		Port:    o.port,
+		User:    o.user,
+	}
+	if t.User == "" {
+		t.User = "root"
+		if strings.Contains(strings.ToLower(ins.OsName), "ubuntu") {
+			t.User = "ubuntu"
+		}
+	}
+	var publicIP, privateIP string
+	for _, ip := range ins.IPSet {
+		if ip.Type == "Private" {
+			if privateIP == "" {
+				privateIP = ip.IP
+			}
+		} else if publicIP == "" {
+			publicIP = ip.IP
+		}
+	}
+	var gssh *pathx.GlobalSSHInfo
+	for idx, g := range gsshs {
+		if publicIP != "" && g.TargetIP == publicIP {
+			gssh = &gsshs[idx]
+			break
+		}
+	}
+
+	switch o.ipType {
+	case "gssh":
+		if gssh == nil {
+			return nil, fmt.Errorf("no GlobalSSH instance found for uhost[%s], see 'ucloud gssh list'", ins.UHostId)
+		}
+	case "public":
+		if publicIP == "" {
+			return nil, fmt.Errorf("uhost[%s] has no public IP", ins.UHostId)
+		}
+		gssh = nil
+	case "private":
+		if privateIP == "" {
+			return nil, fmt.Errorf("uhost[%s] has no private IP", ins.UHostId)
+		}
+		t.Host = privateIP
+		return t, nil
+	case "auto":
+		if o.proxyJump != "" && privateIP != "" {
+			t.Host = privateIP
+			return t, nil
+		}
+	default:
+		return nil, fmt.Errorf("ip-type should be one of auto, public, private and gssh, got %s", o.ipType)
+	}
+
+	if gssh != nil {
+		t.Host = gssh.AcceleratingDomain
+		if gssh.Port != 0 {
+			t.Port = gssh.Port
+		}
+		return t, nil
+	}
+	if publicIP != "" {
+		t.Host = publicIP
+		return t, nil
+	}
+	if privateIP != "" {
+		t.Host = privateIP
+		return t, nil
+	}
+	return nil, fmt.Errorf("uhost[%s] has no IP address", ins.UHostId)
+}
+
+//sshArgs 拼接ssh命令的参数
+func (o *sshOption) sshArgs(t *sshTarget) []string {
+	args := []string{"-p", strconv.Itoa(t.Port)}
+	if o.identityFile != "" {
+		args = append(args, "-i", o.identityFile)
+	}
+	if o.proxyJump != "" {
+		args = append(args, "-J", o.proxyJump)
+	}
+	for _, opt := range o.options {
+		args = append(args, "-o", opt)
+	}
+	return append(args, fmt.Sprintf("%s@%s", t.User, t.Host))
+}
+
+//getSSHGssh 查询GlobalSSH实例, ip-type为auto时忽略错误
+func getSSHGssh(o *sshOption, project string) ([]pathx.GlobalSSHInfo, error) {
+	if !o.needGssh() {
+		return nil, nil
+	}
+	gsshs, err := getAllGssh(project)
+	if err != nil && o.ipType == "auto" {
+		return nil, nil
+	}
+	return gsshs, err
+}
+
+//findUHost 按照资源ID或者名称查找主机
+func findUHost(nameOrID string, req *uhost.DescribeUHostInstanceRequest) (*uhost.UHostInstanceSet, error) {
+	nameOrID = base.PickResourceID(nameOrID)
+	uhosts, err := getAllUHosts(req, true, false)
+	if err != nil {
+		return nil, err
+	}
+	matched := []uhost.UHostInstanceSet{}
+	for _, ins := range uhosts {
+		if ins.UHostId == nameOrID {
+			return &ins, nil
+		}
+		if ins.Name == nameOrID {
+			matched = append(matched, ins)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("uhost[%s] not found in region %s", nameOrID, *req.Region)
+	}
+	if len(matched) > 1 {
+		ids := []string{}
+		for _, ins := range matched {
+			ids = append(ids, ins.UHostId)
+		}
+		return nil, fmt.Errorf("more than one uhost named %s: %s, please use resource id instead", nameOrID, strings.Join(ids, ", "))
+	}
+	return &matched[0], nil
+}
+
+//NewCmdUHostSSH ucloud uhost ssh
+func NewCmdUHostSSH(out io.Writer) *cobra.Command {
+	var o *sshOption
+	req := base.BizClient.NewDescribeUHostInstanceRequest()
+	cmd := &cobra.Command{
+		Use:   "ssh <uhost-id or name> [-- command]",
+		Short: "Log in to uhost or execute a command on it with the system ssh",
+		Long: `Log in to uhost or execute a command on it with the system ssh.
+The GlobalSSH endpoint, public IP or private IP of the uhost is picked according to ip-type`,
+		Example: "ucloud uhost ssh web-1; ucloud uhost ssh uhost-xxx -i ~/.ssh/id_rsa -- uptime",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			ins, err := findUHost(args[0], req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			gsshs, err := getSSHGssh(o, *req.ProjectId)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			t, err := o.target(ins, gsshs)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			sshArgs := append(o.sshArgs(t), args[1:]...)
+			base.LogInfo(fmt.Sprintf("ssh %s", strings.Join(sshArgs, " ")))
+			sshCmd := exec.Command("ssh", sshArgs...)
+			sshCmd.Stdin = os.Stdin
+			sshCmd.Stdout = os.Stdout
+			sshCmd.Stderr = os.Stderr
+			err = sshCmd.Run()
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				if ws, ok := exitErr.Sys().(interface{ ExitStatus() int }); ok {
+					os.Exit(ws.ExitStatus())
+				}
+				os.Exit(1)
+			}
+			if err != nil {
+				base.HandleError(err)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	o = bindSSHOption(flags)
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	return cmd
+}
+
+//NewCmdUHostSSHConfig ucloud uhost ssh-config
+func NewCmdUHostSSHConfig(out io.Writer) *cobra.Command {
+	var o *sshOption
+	req := base.BizClient.NewDescribeUHostInstanceRequest()
+	cmd := &cobra.Command{
+		Use:   "ssh-config",
+		Short: "Print Host blocks of ssh_config for uhost instances",
+		Long: `Print Host blocks of ssh_config for uhost instances. The name of uhost is used as the Host alias.
+Append the output to ~/.ssh/config and then log in with 'ssh <name>'`,
+		Example: "ucloud uhost ssh-config --group prod --proxy-jump root@bastion.example.com >> ~/.ssh/config",
+		Run: func(c *cobra.Command, args []string) {
+			for idx, id := range req.UHostIds {
+				req.UHostIds[idx] = base.PickResourceID(id)
+			}
+			uhosts, err := getAllUHosts(req, true, false)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			gsshs, err := getSSHGssh(o, *req.ProjectId)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			nameCount := make(map[string]int)
+			for _, ins := range uhosts {
+				nameCount[sshHostAlias(ins.Name)]++
+			}
+			fmt.Fprintf(out, "# generated by 'ucloud uhost ssh-config', region %s\n", *req.Region)
+			for _, ins := range uhosts {
+				t, err := o.target(&ins, gsshs)
+				if err != nil {
+					fmt.Fprintf(out, "# skip uhost[%s]: %v\n", ins.UHostId, err)
+					continue
+				}
+				alias := sshHostAlias(ins.Name)
+				if alias == "" || nameCount[alias] > 1 {
+					alias = strings.TrimPrefix(alias+"-"+ins.UHostId, "-")
+				}
+				fmt.Fprintf(out, "\nHost %s\n", alias)
+				fmt.Fprintf(out, "    HostName %s\n", t.Host)
+				fmt.Fprintf(out, "    User %s\n", t.User)
+				fmt.Fprintf(out, "    Port %d\n", t.Port)
+				if o.identityFile != "" {
+					fmt.Fprintf(out, "    IdentityFile %s\n", o.identityFile)
+				}
+				if o.proxyJump != "" {
+					fmt.Fprintf(out, "    ProxyJump %s\n", o.proxyJump)
+				}
+				for _, opt := range o.options {
+					kv := strings.SplitN(opt, "=", 2)
+					if len(kv) == 2 {
+						fmt.Fprintf(out, "    %s %s\n", kv[0], kv[1])
+					}
+				}
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&req.UHostIds, "uhost-id", nil, "Optional. Resource ID of uhost instances. All uhost instances in the region by default")
+	bindGroup(req, flags)
+	o = bindSSHOption(flags)
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	return cmd
+}
+
+//sshHostAlias 把主机名称转换为可用于ssh_config的别名
+func sshHostAlias(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '*' || r == '?' || r == '!' || r == ',' {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+}
+
+//execItem 在单台主机上执行命令的任务
+type execItem struct {
+	request.CommonBase
+	target  *sshTarget
+	args    []string
+	timeout time.Duration
+	result  *execResult
+}
+
+//execResult 单台主机的执行结果
+type execResult struct {
+	ResourceID string `json:"resource_id"`
+	Name       string `json:"name"`
+	Host       string `json:"host"`
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Error      string `json:"error,omitempty"`
+}
+
+//ExecRow 执行结果表格行
+type ExecRow struct {
+	ResourceID string
+	Name       string
+	Host       string
+	ExitCode   int
+	Output     string
+}
+
+//NewCmdUHostExec ucloud uhost exec
+func NewCmdUHostExec(out io.Writer) *cobra.Command {
+	var o *sshOption
+	var filters []string
+	var parallel int
+	var timeout time.Duration
+	var report string
+	var yes bool
+	req := base.BizClient.NewDescribeUHostInstanceRequest()
+	cmd := &cobra.Command{
+		Use:   "exec [flags] -- <command>",
+		Short: "Execute a command on multiple uhost instances over ssh concurrently",
+		Long: `Execute a command on multiple uhost instances over ssh concurrently, and print a summary or a report in json format.
+Uhost instances are selected by --uhost-id or --filter. The system ssh is used in batch mode, so the authentication should not need any interaction`,
+		Example: "ucloud uhost exec --filter Group=web --parallel 5 -- 'systemctl restart nginx'",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			for idx, id := range req.UHostIds {
+				req.UHostIds[idx] = base.PickResourceID(id)
+			}
+			uhosts, err := getAllUHosts(req, true, false)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			uhosts, err = filterUHosts(uhosts, filters)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if len(uhosts) == 0 {
+				fmt.Fprintln(out, "no uhost instance matched")
+				return
+			}
+			gsshs, err := getSSHGssh(o, *req.ProjectId)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if !yes {
+				sure, err := ux.Prompt(fmt.Sprintf("Are you sure you want to execute %q on %d uhost instance(s)?", strings.Join(args, " "), len(uhosts)))
+				if err != nil {
+					base.Cxt.Println(err)
+					return
+				}
+				if !sure {
+					return
+				}
+			}
+
+			options := append([]string{"BatchMode=yes"}, o.options...)
+			batchOpt := *o
+			batchOpt.options = options
+			items := make([]*execItem, len(uhosts))
+			reqs := make([]request.Common, len(uhosts))
+			for idx := range uhosts {
+				ins := &uhosts[idx]
+				item := &execItem{
+					timeout: timeout,
+					result: &execResult{
+						ResourceID: ins.UHostId,
+						Name:       ins.Name,
+						ExitCode:   -1,
+					},
+				}
+				t, err := o.target(ins, gsshs)
+				if err != nil {
+					item.result.Error = err.Error()
+				} else {
+					item.target = t
+					item.result.Host = t.Host
+					item.args = append(batchOpt.sshArgs(t), "--")
+					item.args = append(item.args, args...)
+				}
+				items[idx] = item
+				reqs[idx] = item
+			}
+			coAction := newConcurrentAction(reqs, execAction)
+			coAction.setParallel(parallel)
+			coAction.setQuiet(true)
+			coAction.Do()
+
+			results := make([]*execResult, len(items))
+			rows := make([]ExecRow, len(items))
+			success := 0
+			for idx, item := range items {
+				r := item.result
+				results[idx] = r
+				if r.ExitCode == 0 {
+					success++
+				}
+				output := strings.TrimSpace(r.Stdout + r.Stderr)
+				if r.Error != "" {
+					output = strings.TrimSpace(r.Error + " " + output)
+				}
+				rows[idx] = ExecRow{
+					ResourceID: r.ResourceID,
+					Name:       r.Name,
+					Host:       r.Host,
+					ExitCode:   r.ExitCode,
+					Output:     firstLine(output, 80),
+				}
+			}
+			if report != "" {
+				content, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+				err = ioutil.WriteFile(report, content, base.LocalFileMode)
+				if err != nil {
+					base.HandleError(err)
+					return
+				}
+			}
+			if global.JSON {
+				base.PrintJSON(results, out)
+			} else {
+				base.PrintTable(rows, []string{"ResourceID", "Name", "Host", "ExitCode", "Output"})
+				fmt.Fprintf(out, "total:%d, success:%d, fail:%d\n", len(items), success, len(items)-success)
+			}
+			if report != "" {
+				fmt.Fprintf(out, "report written to %s\n", report)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&req.UHostIds, "uhost-id", nil, "Optional. Resource ID of uhost instances to execute the command on")
+	flags.StringArrayVar(&filters, "filter", nil, "Optional. Select uhost instances by Key=Value, where Key is one of ResourceID, Name, Group, State, Zone, IP and Value supports wildcard '*'. Repeat it to combine multiple filters")
+	flags.IntVar(&parallel, "parallel", 10, "Optional. The maximum number of uhost instances to execute the command on at the same time")
+	flags.DurationVar(&timeout, "timeout", 0, "Optional. Timeout of the command on each uhost, such as 30s, 5m. No timeout by default")
+	flags.StringVar(&report, "report", "", "Optional. Path of file to write the report in json format, including the whole stdout and stderr of each uhost")
+	o = bindSSHOption(flags)
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	flags.BoolVarP(&yes, "yes", "y", false, "Optional. Do not prompt for confirmation")
+	flags.SetFlagValues("filter", "ResourceID=", "Name=", "Group=", "State=", "Zone=", "IP=")
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	return cmd
+}
+
+func execAction(creq request.Common) (bool, []string) {
+	item := creq.(*execItem)
+	r := item.result
+	block := ux.NewBlock()
+	ux.Doc.Append(block)
+	if item.target == nil {
+		block.Append(fmt.Sprintf("uhost[%s] skipped: %s", r.ResourceID, r.Error))
+		return false, []string{r.Error}
+	}
+	logs := []string{fmt.Sprintf("ssh %s", strings.Join(item.args, " "))}
+	text := fmt.Sprintf("uhost[%s] %s executing", r.ResourceID, r.Host)
+	block.Append(text + "...")
+
+	ctx := context.Background()
+	if item.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, item.timeout)
+		defer cancel()
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	sshCmd := exec.CommandContext(ctx, "ssh", item.args...)
+	sshCmd.Stdout = stdout
+	sshCmd.Stderr = stderr
+	err := sshCmd.Run()
+	r.Stdout = stdout.String()
+	r.Stderr = stderr.String()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		r.ExitCode = 1
+		if ws, ok := exitErr.Sys().(interface{ ExitStatus() int }); ok {
+			r.ExitCode = ws.ExitStatus()
+		}
+		if ctx.Err() != nil {
+			r.Error = fmt.Sprintf("timeout after %s", item.timeout)
+		}
+	} else if err != nil {
+		r.Error = err.Error()
+	} else {
+		r.ExitCode = 0
+	}
+	logs = append(logs, fmt.Sprintf("exit code: %d, stdout: %q, stderr: %q", r.ExitCode, r.Stdout, r.Stderr))
+	if r.ExitCode == 0 {
+		block.Update(text+"...done", 0)
+		return true, logs
+	}
+	if r.Error != "" {
+		block.Update(fmt.Sprintf("%s...%s", text, r.Error), 0)
+	} else {
+		block.Update(fmt.Sprintf("%s...exit code %d", text, r.ExitCode), 0)
+	}
+	return false, logs
+}
+
+//filterUHosts 按照Key=Value过滤主机, 多个条件同时满足
+func filterUHosts(uhosts []uhost.UHostInstanceSet, filters []string) ([]uhost.UHostInstanceSet, error) {
+	type condition struct{ key, pattern string }
+	conds := []condition{}
+	for _, f := range filters {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("filter %q should be in the format of Key=Value", f)
+		}
+		switch kv[0] {
+		case "ResourceID", "Name", "Group", "State", "Zone", "IP":
+		default:
+			return nil, fmt.Errorf("key of filter %q should be one of ResourceID, Name, Group, State, Zone and IP", f)
+		}
+		if _, err := path.Match(kv[1], ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern of filter %q: %v", f, err)
+		}
+		conds = append(conds, condition{kv[0], kv[1]})
+	}
+	result := []uhost.UHostInstanceSet{}
+	for _, ins := range uhosts {
+		matched := true
+		for _, cond := range conds {
+			var values []string
+			switch cond.key {
+			case "ResourceID":
+				values = []string{ins.UHostId}
+			case "Name":
+				values = []string{ins.Name}
+			case "Group":
+				values = []string{ins.Tag}
+			case "State":
+				values = []string{ins.State}
+			case "Zone":
+				values = []string{ins.Zone}
+			case "IP":
+				for _, ip := range ins.IPSet {
+					values = append(values, ip.IP)
+				}
+			}
+			ok := false
+			for _, v := range values {
+				if m, _ := path.Match(cond.pattern, v); m {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, ins)
+		}
+	}
+	return result, nil
+}
+
+//firstLine 取第一行, 超过max个字符时截断
+func firstLine(s string, max int) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		s = s[:idx] + " ..."
+	}
+	if runes := []rune(s); len(runes) > max {
+		s = string(runes[:max]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,492 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	sdk "github.com/ucloud/ucloud-sdk-go/ucloud"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+)
+
+//uhostTemplateFile 默认的主机模板文件
+var uhostTemplateFile = base.GetConfigDir() + "/uhost_templates.json"
+
+//uhostTemplateExcludedFlags 不保存到模板中的uhost create选项
+var uhostTemplateExcludedFlags = map[string]bool{
+	"password":      true,
+	"count":         true,
+	"async":         true,
+	"bind-eip":      true,
+	"template":      true,
+	"template-file": true,
+}
+
+//uhostTemplate 主机模板, Params的key为uhost create的选项名
+type uhostTemplate struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Params      map[string]string `json:"params"`
+	DataDisks   []string          `json:"data_disks,omitempty"`
+}
+
+//UHostTemplateRow 主机模板表格行
+type UHostTemplateRow struct {
+	TemplateName string
+	Config       string
+	ImageID      string
+	DataDisks    string
+	Description  string
+}
+
+//NewCmdUHostTemplate ucloud uhost template
+func NewCmdUHostTemplate(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Create, list, show and delete templates of uhost",
+		Long: `Create, list, show and delete templates of uhost. A template is a named set of 'ucloud uhost create' parameters, excluding password.
+Templates are stored in ` + uhostTemplateFile + ` by default. Assign --template-file to use a shared file`,
+	}
+	cmd.AddCommand(NewCmdUHostTemplateCreate(out))
+	cmd.AddCommand(NewCmdUHostTemplateFromUHost(out))
+	cmd.AddCommand(NewCmdUHostTemplateList(out))
+	cmd.AddCommand(NewCmdUHostTemplateShow(out))
+	cmd.AddCommand(NewCmdUHostTemplateDelete(out))
+	return cmd
+}
+
+//NewCmdUHostTemplateCreate ucloud uhost template create
+func NewCmdUHostTemplateCreate(out io.Writer) *cobra.Command {
+	var name, description, file string
+	var overwrite bool
+	createFlags := NewCmdUHostCreate().Flags()
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create a template with parameters of 'ucloud uhost create'",
+		Long:    "Create a template with parameters of 'ucloud uhost create'. Only the assigned parameters are saved, password is never saved",
+		Example: "ucloud uhost template create --template-name web-small --cpu 2 --memory-gb 4 --image-id uimage-xxx --data-disk CLOUD_SSD:100",
+		Run: func(c *cobra.Command, args []string) {
+			tpl := &uhostTemplate{
+				Name:        name,
+				Description: description,
+				Params:      make(map[string]string),
+			}
+			c.Flags().Visit(func(f *pflag.Flag) {
+				if createFlags.Lookup(f.Name) == nil || uhostTemplateExcludedFlags[f.Name] {
+					return
+				}
+				if f.Name == "data-disk" {
+					tpl.DataDisks, _ = c.Flags().GetStringArray(f.Name)
+					return
+				}
+				tpl.Params[f.Name] = f.Value.String()
+			})
+			for _, d := range tpl.DataDisks {
+				if _, err := parseDataDisk(d); err != nil {
+					base.HandleError(err)
+					return
+				}
+			}
+			err := saveUHostTemplate(file, tpl, overwrite)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			fmt.Fprintf(out, "template[%s] saved to %s\n", name, file)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&name, "template-name", "", "Required. Name of the template")
+	flags.StringVar(&description, "description", "", "Optional. Description of the template")
+	bindUHostTemplateFile(&file, flags)
+	flags.BoolVar(&overwrite, "overwrite", false, "Optional. Overwrite the template if it already exists")
+	createFlags.VisitAll(func(f *pflag.Flag) {
+		if uhostTemplateExcludedFlags[f.Name] {
+			return
+		}
+		flag := *f
+		flag.Annotations = make(map[string][]string)
+		for k, v := range f.Annotations {
+			if k != cobra.BashCompOneRequiredFlag {
+				flag.Annotations[k] = v
+			}
+		}
+		flags.AddFlag(&flag)
+	})
+	cmd.MarkFlagRequired("template-name")
+
+	return cmd
+}
+
+//NewCmdUHostTemplateFromUHost ucloud uhost template from-uhost
+func NewCmdUHostTemplateFromUHost(out io.Writer) *cobra.Command {
+	var uhostID, name, description, file string
+	var overwrite bool
+	req := base.BizClient.NewCreateUHostInstanceRequest()
+	cmd := &cobra.Command{
+		Use:     "from-uhost [uhost-id]",
+		Short:   "Create a template from the configuration of an existing uhost",
+		Long:    "Create a template from the configuration of an existing uhost, excluding bound eip and udisk",
+		Example: "ucloud uhost template from-uhost uhost-xxx --template-name web-small",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 1 {
+				uhostID = args[0]
+			}
+			if uhostID == "" {
+				base.Cxt.Println("Error, uhost-id is required")
+				return
+			}
+			uhostID = base.PickResourceID(uhostID)
+			ins, err := fillCloneRequest(req, uhostID)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			tpl := uhostTemplateFromRequest(req, ins)
+			tpl.Name = name
+			tpl.Description = description
+			if tpl.Description == "" {
+				tpl.Description = fmt.Sprintf("derived from uhost[%s]", uhostID)
+			}
+			err = saveUHostTemplate(file, tpl, overwrite)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			fmt.Fprintf(out, "template[%s] saved to %s\n", name, file)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&uhostID, "uhost-id", "", "Optional. Resource ID of the uhost to derive the template from. It can also be passed as an argument")
+	flags.StringVar(&name, "template-name", "", "Required. Name of the template")
+	flags.StringVar(&description, "description", "", "Optional. Description of the template")
+	bindUHostTemplateFile(&file, flags)
+	flags.BoolVar(&overwrite, "overwrite", false, "Optional. Overwrite the template if it already exists")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZone(req, flags)
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	cmd.MarkFlagRequired("template-name")
+
+	return cmd
+}
+
+//NewCmdUHostTemplateList ucloud uhost template list
+func NewCmdUHostTemplateList(out io.Writer) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List templates of uhost",
+		Long:  "List templates of uhost",
+		Run: func(c *cobra.Command, args []string) {
+			templates, err := loadUHostTemplates(file)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			list := make([]UHostTemplateRow, 0, len(templates))
+			for _, tpl := range templates {
+				row := UHostTemplateRow{
+					TemplateName: tpl.Name,
+					ImageID:      tpl.Params["image-id"],
+					DataDisks:    strings.Join(tpl.DataDisks, ","),
+					Description:  tpl.Description,
+				}
+				if cpu, ok := tpl.Params["cpu"]; ok {
+					row.Config = fmt.Sprintf("cpu:%s ", cpu)
+				}
+				if memory, ok := tpl.Params["memory-gb"]; ok {
+					row.Config += fmt.Sprintf("memory:%sG", memory)
+				}
+				row.Config = strings.TrimSpace(row.Config)
+				list = append(list, row)
+			}
+			base.PrintList(list, out)
+		},
+	}
+	bindUHostTemplateFile(&file, cmd.Flags())
+	return cmd
+}
+
+//NewCmdUHostTemplateShow ucloud uhost template show
+func NewCmdUHostTemplateShow(out io.Writer) *cobra.Command {
+	var name, file string
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Display parameters of a template",
+		Long:  "Display parameters of a template",
+		Run: func(c *cobra.Command, args []string) {
+			tpl, err := getUHostTemplate(file, name)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			if global.JSON {
+				base.PrintJSON(tpl, out)
+				return
+			}
+			rows := []base.DescribeTableRow{
+				{Attribute: "TemplateName", Content: tpl.Name},
+				{Attribute: "Description", Content: tpl.Description},
+			}
+			keys := make([]string, 0, len(tpl.Params))
+			for k := range tpl.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				rows = append(rows, base.DescribeTableRow{Attribute: "--" + k, Content: tpl.Params[k]})
+			}
+			for _, d := range tpl.DataDisks {
+				rows = append(rows, base.DescribeTableRow{Attribute: "--data-disk", Content: d})
+			}
+			base.PrintList(rows, out)
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&name, "template-name", "", "Required. Name of the template")
+	bindUHostTemplateFile(&file, flags)
+	flags.SetFlagValuesFunc("template-name", func() []string {
+		return getUHostTemplateNames(file)
+	})
+	cmd.MarkFlagRequired("template-name")
+	return cmd
+}
+
+//NewCmdUHostTemplateDelete ucloud uhost template delete
+func NewCmdUHostTemplateDelete(out io.Writer) *cobra.Command {
+	var names []string
+	var file string
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete templates of uhost",
+		Long:  "Delete templates of uhost",
+		Run: func(c *cobra.Command, args []string) {
+			templates, err := loadUHostTemplates(file)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			deleted := make(map[string]bool)
+			for _, name := range names {
+				deleted[name] = true
+			}
+			left := make([]*uhostTemplate, 0, len(templates))
+			for _, tpl := range templates {
+				if deleted[tpl.Name] {
+					delete(deleted, tpl.Name)
+					continue
+				}
+				left = append(left, tpl)
+			}
+			for _, name := range names {
+				if deleted[name] {
+					base.HandleError(fmt.Errorf("template[%s] not exist", name))
+					return
+				}
+			}
+			err = writeUHostTemplates(file, left)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			for _, name := range names {
+				fmt.Fprintf(out, "template[%s] deleted\n", name)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringSliceVar(&names, "template-name", nil, "Required. Names of the templates to delete")
+	bindUHostTemplateFile(&file, flags)
+	flags.SetFlagValuesFunc("template-name", func() []string {
+		return getUHostTemplateNames(file)
+	})
+	cmd.MarkFlagRequired("template-name")
+	return cmd
+}
+
+func bindUHostTemplateFile(file *string, flags *pflag.FlagSet) {
+	flags.StringVar(file, "template-file", uhostTemplateFile, "Optional. Path of the file which stores templates. Assign a shared file to share templates among team members")
+	flags.SetFlagValuesFunc("template-file", func() []string {
+		return base.GetFileList(".json")
+	})
+}
+
+//applyUHostTemplate 把模板中的参数设置到未指定的uhost create选项上
+func applyUHostTemplate(flags *pflag.FlagSet, file, name string) error {
+	tpl, err := getUHostTemplate(file, name)
+	if err != nil {
+		return err
+	}
+	for k, v := range tpl.Params {
+		if flags.Lookup(k) == nil {
+			return fmt.Errorf("param %s of template[%s] is not supported by 'ucloud uhost create'", k, name)
+		}
+		if flags.Changed(k) {
+			continue
+		}
+		if err := flags.Set(k, v); err != nil {
+			return fmt.Errorf("invalid param %s=%s of template[%s]: %v", k, v, name, err)
+		}
+	}
+	legacyDisk := flags.Changed("data-disk-type") || flags.Changed("data-disk-size-gb") || flags.Changed("data-disk-backup-type")
+	if !flags.Changed("data-disk") && !legacyDisk {
+		for _, d := range tpl.DataDisks {
+			if err := flags.Set("data-disk", d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//uhostTemplateFromRequest 把克隆主机的请求转换为模板参数
+func uhostTemplateFromRequest(req *uhost.CreateUHostInstanceRequest, ins *uhost.UHostInstanceSet) *uhostTemplate {
+	tpl := &uhostTemplate{
+		Params: make(map[string]string),
+	}
+	setParam := func(name string, value *string) {
+		if value != nil && *value != "" {
+			tpl.Params[name] = *value
+		}
+	}
+	tpl.Params["cpu"] = strconv.Itoa(sdk.IntValue(req.CPU))
+	tpl.Params["memory-gb"] = strconv.Itoa(sdk.IntValue(req.Memory) / 1024)
+	setParam("image-id", req.ImageId)
+	setParam("vpc-id", req.VPCId)
+	setParam("subnet-id", req.SubnetId)
+	setParam("firewall-id", req.SecurityGroupId)
+	setParam("charge-type", req.ChargeType)
+	setParam("net-capability", req.NetCapability)
+	setParam("group", req.Tag)
+	setParam("machine-type", &ins.MachineType)
+	setParam("isolation-group", &ins.IsolationGroup)
+	if ins.GPU > 0 {
+		tpl.Params["gpu"] = strconv.Itoa(ins.GPU)
+	}
+	if ins.HotplugFeature {
+		tpl.Params["hotplug"] = "true"
+	}
+	for _, disk := range ins.DiskSet {
+		//单独挂载的云硬盘不属于主机配置, 不写入模板
+		if disk.Type == "Udisk" {
+			continue
+		}
+		backup := disk.BackupType
+		if backup == "" {
+			backup = "NONE"
+		}
+		if disk.IsBoot == "True" {
+			tpl.Params["os-disk-type"] = disk.DiskType
+			tpl.Params["os-disk-size-gb"] = strconv.Itoa(disk.Size)
+			tpl.Params["os-disk-backup-type"] = backup
+			continue
+		}
+		tpl.DataDisks = append(tpl.DataDisks, fmt.Sprintf("%s:%d:%s", disk.DiskType, disk.Size, backup))
+	}
+	return tpl
+}
+
+func loadUHostTemplates(file string) ([]*uhostTemplate, error) {
+	templates := []*uhostTemplate{}
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return templates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return templates, nil
+	}
+	err = json.Unmarshal(content, &templates)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", file, err)
+	}
+	return templates, nil
+}
+
+func writeUHostTemplates(file string, templates []*uhostTemplate) error {
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	content, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, base.LocalFileMode)
+}
+
+func saveUHostTemplate(file string, tpl *uhostTemplate, overwrite bool) error {
+	templates, err := loadUHostTemplates(file)
+	if err != nil {
+		return err
+	}
+	for idx, t := range templates {
+		if t.Name == tpl.Name {
+			if !overwrite {
+				return fmt.Errorf("template[%s] already exists, assign --overwrite to replace it", tpl.Name)
+			}
+			templates[idx] = tpl
+			return writeUHostTemplates(file, templates)
+		}
+	}
+	return writeUHostTemplates(file, append(templates, tpl))
+}
+
+func getUHostTemplate(file, name string) (*uhostTemplate, error) {
+	templates, err := loadUHostTemplates(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, tpl := range templates {
+		if tpl.Name == name {
+			return tpl, nil
+		}
+	}
+	return nil, fmt.Errorf("template[%s] not exist in %s", name, file)
+}
+
+func getUHostTemplateNames(file string) []string {
+	templates, err := loadUHostTemplates(file)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(templates))
+	for _, tpl := range templates {
+		names = append(names, tpl.Name)
+	}
+	return names
+}
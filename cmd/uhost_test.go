@@ -228,3 +228,67 @@ func TestUhost(t *testing.T) {
 	deleteT.run(t)
 
 }
+
+type parseDataDiskTest struct {
+	value              string
+	expectedType       string
+	expectedSize       int
+	expectedBackupType string
+	expectedErr        bool
+}
+
+func (test *parseDataDiskTest) run(t *testing.T) {
+	disk, err := parseDataDisk(test.value)
+	if test.expectedErr {
+		if err == nil {
+			t.Errorf("parseDataDisk(%q), expected error, got nil", test.value)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("parseDataDisk(%q), unexpected error: %v", test.value, err)
+	}
+	if *disk.Type != test.expectedType || *disk.Size != test.expectedSize || *disk.BackupType != test.expectedBackupType || *disk.IsBoot != "False" {
+		t.Errorf("parseDataDisk(%q), expected %s:%d:%s, got %s:%d:%s", test.value, test.expectedType, test.expectedSize, test.expectedBackupType, *disk.Type, *disk.Size, *disk.BackupType)
+	}
+}
+
+func TestParseDataDisk(t *testing.T) {
+	tests := []parseDataDiskTest{
+		{value: "cloud_ssd:100", expectedType: "CLOUD_SSD", expectedSize: 100, expectedBackupType: "NONE"},
+		{value: "CLOUD_NORMAL:200:dataark", expectedType: "CLOUD_NORMAL", expectedSize: 200, expectedBackupType: "DATAARK"},
+		{value: "CLOUD_RSSD:50:", expectedType: "CLOUD_RSSD", expectedSize: 50, expectedBackupType: "NONE"},
+		{value: "CLOUD_SSD", expectedErr: true},
+		{value: ":100", expectedErr: true},
+		{value: "CLOUD_SSD:abc", expectedErr: true},
+		{value: "CLOUD_SSD:0", expectedErr: true},
+		{value: "CLOUD_SSD:100:NONE:extra", expectedErr: true},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+type isPublicKeyTest struct {
+	value    string
+	expected bool
+}
+
+func (test *isPublicKeyTest) run(t *testing.T) {
+	if got := isPublicKey(test.value); got != test.expected {
+		t.Errorf("isPublicKey(%q), expected %t, got %t", test.value, test.expected, got)
+	}
+}
+
+func TestIsPublicKey(t *testing.T) {
+	tests := []isPublicKeyTest{
+		{value: "ssh-rsa AAAAB3NzaC1yc2E user@host", expected: true},
+		{value: "  ssh-ed25519 AAAAC3NzaC1lZDI1NTE5\n", expected: true},
+		{value: "ecdsa-sha2-nistp256 AAAAE2VjZHNh", expected: true},
+		{value: "~/.ssh/id_rsa.pub", expected: false},
+		{value: "/tmp/ssh-key.pub", expected: false},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
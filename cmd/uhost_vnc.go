@@ -0,0 +1,161 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/model/status"
+)
+
+//NewCmdUHostVnc ucloud uhost vnc
+func NewCmdUHostVnc(out io.Writer) *cobra.Command {
+	var proxy bool
+	var listen string
+	req := base.BizClient.NewGetUHostInstanceVncInfoRequest()
+	cmd := &cobra.Command{
+		Use:   "vnc",
+		Short: "Display VNC console information of uhost, or proxy it to localhost",
+		Long: `Display VNC console information of uhost, or proxy it to localhost.
+It is useful to log in to the uhost when the network or sshd of the uhost is misconfigured`,
+		Example: "ucloud uhost vnc --uhost-id uhost-xxx --proxy --listen 127.0.0.1:5900",
+		Run: func(c *cobra.Command, args []string) {
+			*req.UHostId = base.PickResourceID(*req.UHostId)
+			resp, err := base.BizClient.GetUHostInstanceVncInfo(req)
+			if err != nil {
+				base.HandleError(err)
+				return
+			}
+			remote := net.JoinHostPort(resp.VncIP, strconv.Itoa(resp.VncPort))
+			if !proxy {
+				if global.JSON {
+					base.PrintJSON(resp, out)
+					return
+				}
+				fmt.Fprintf(out, "VncIP: %s\nVncPort: %d\nVncPassword: %s\nURL: %s\n", resp.VncIP, resp.VncPort, resp.VncPassword, vncURL(remote, resp.VncPassword))
+				return
+			}
+			err = proxyVnc(listen, remote, resp.VncPassword, out)
+			if err != nil {
+				base.HandleError(err)
+			}
+		},
+	}
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	req.UHostId = flags.String("uhost-id", "", "Required. Resource ID of the uhost")
+	flags.BoolVar(&proxy, "proxy", false, "Optional. Run a local TCP proxy to the VNC server, so that a VNC viewer can connect to it on localhost. Press Ctrl-C to stop")
+	flags.StringVar(&listen, "listen", "127.0.0.1:5900", "Optional. Local address the proxy listens on. It takes effect when proxy is assigned")
+	bindProjectID(req, flags)
+	bindRegion(req, flags)
+	bindZoneEmpty(req, flags)
+	flags.SetFlagValues("proxy", "true", "false")
+	flags.SetFlagValuesFunc("uhost-id", func() []string {
+		return getUhostList([]string{status.HOST_RUNNING, status.HOST_STOPPED, status.HOST_FAIL}, *req.ProjectId, *req.Region, *req.Zone)
+	})
+	cmd.MarkFlagRequired("uhost-id")
+	return cmd
+}
+
+func vncURL(addr, password string) string {
+	u := url.URL{
+		Scheme: "vnc",
+		Host:   addr,
+	}
+	if password != "" {
+		u.User = url.UserPassword("", password)
+	}
+	return u.String()
+}
+
+//proxyVnc 在本地监听, 把连接转发到VNC服务器, 直到按下Ctrl-C
+func proxyVnc(listen, remote, password string, out io.Writer) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	fmt.Fprintf(out, "proxying %s to %s, connect your VNC viewer to %s. Press Ctrl-C to stop\n", listener.Addr(), remote, vncURL(listener.Addr().String(), password))
+	if password != "" {
+		fmt.Fprintf(out, "VNC password: %s\n", password)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	//interrupted 关闭说明监听是被Ctrl-C关闭的, done 关闭时结束等待信号的goroutine
+	interrupted := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupt:
+			close(interrupted)
+			listener.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		local, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-interrupted:
+				return nil
+			default:
+				return err
+			}
+		}
+		go func() {
+			err := pipeConn(local, remote)
+			if err != nil {
+				base.LogError(fmt.Sprintf("proxy vnc connection from %s failed: %v", local.RemoteAddr(), err))
+				fmt.Fprintf(out, "connection from %s closed: %v\n", local.RemoteAddr(), err)
+				return
+			}
+			fmt.Fprintf(out, "connection from %s closed\n", local.RemoteAddr())
+		}()
+	}
+}
+
+//pipeConn 双向转发数据, 任意一端关闭后返回
+func pipeConn(local net.Conn, remote string) error {
+	defer local.Close()
+	conn, err := net.Dial("tcp", remote)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(conn, local)
+		done <- err
+	}()
+	go func() {
+		_, err := io.Copy(local, conn)
+		done <- err
+	}()
+	return <-done
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+)
+
+type vncURLTest struct {
+	addr        string
+	password    string
+	expectedURL string
+}
+
+func (test *vncURLTest) run(t *testing.T) {
+	u := vncURL(test.addr, test.password)
+	if u != test.expectedURL {
+		t.Errorf("vncURL(%q, %q), expected %q, got %q", test.addr, test.password, test.expectedURL, u)
+	}
+}
+
+func TestVncURL(t *testing.T) {
+	tests := []vncURLTest{
+		{addr: "127.0.0.1:5900", password: "", expectedURL: "vnc://127.0.0.1:5900"},
+		{addr: "127.0.0.1:5900", password: "secret", expectedURL: "vnc://:secret@127.0.0.1:5900"},
+		{addr: "10.0.0.1:5901", password: "a@b/c", expectedURL: "vnc://:a%40b%2Fc@10.0.0.1:5901"},
+		{addr: "[::1]:5900", password: "", expectedURL: "vnc://[::1]:5900"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -52,42 +52,44 @@ type uphostRow struct {
 func NewCmdUPHostList(out io.Writer) *cobra.Command {
 	ids := []string{}
 	req := base.BizClient.NewDescribePHostRequest()
+	var watch *watchOption
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List UPHost instances",
 		Long:  "List UPHost instances",
 		Run: func(c *cobra.Command, args []string) {
-			resp, err := base.BizClient.DescribePHost(req)
-			if err != nil {
-				base.HandleError(err)
-				return
-			}
-			list := make([]uphostRow, 0)
-			for _, ins := range resp.PHostSet {
-				row := uphostRow{
-					ResourceID: ins.PHostId,
-					Name:       ins.Name,
-					Config:     fmt.Sprintf("core:%d memory:%dG", ins.CPUSet.CoreCount, ins.Memory/1024),
-					Group:      ins.Tag,
-					HostType:   ins.PHostType,
-					Status:     ins.PMStatus,
-					Image:      ins.ImageName,
+			printRows(watch, func() (interface{}, []string, error) {
+				resp, err := base.BizClient.DescribePHost(req)
+				if err != nil {
+					return nil, nil, err
 				}
-				for _, ip := range ins.IPSet {
-					if ip.OperatorName == "Private" {
-						row.PrivateIP = ip.IPAddr
-					} else {
-						row.PublicIP = ip.IPAddr + " " + ip.OperatorName
+				list := make([]uphostRow, 0)
+				for _, ins := range resp.PHostSet {
+					row := uphostRow{
+						ResourceID: ins.PHostId,
+						Name:       ins.Name,
+						Config:     fmt.Sprintf("core:%d memory:%dG", ins.CPUSet.CoreCount, ins.Memory/1024),
+						Group:      ins.Tag,
+						HostType:   ins.PHostType,
+						Status:     ins.PMStatus,
+						Image:      ins.ImageName,
 					}
-				}
-				for _, disk := range ins.DiskSet {
-					if disk.Name == "data" {
-						row.Config += fmt.Sprintf(" data-disk:%dG %s", disk.Space, disk.Type)
+					for _, ip := range ins.IPSet {
+						if ip.OperatorName == "Private" {
+							row.PrivateIP = ip.IPAddr
+						} else {
+							row.PublicIP = ip.IPAddr + " " + ip.OperatorName
+						}
+					}
+					for _, disk := range ins.DiskSet {
+						if disk.Name == "data" {
+							row.Config += fmt.Sprintf(" data-disk:%dG %s", disk.Space, disk.Type)
+						}
 					}
+					list = append(list, row)
 				}
-				list = append(list, row)
-			}
-			base.PrintList(list, out)
+				return list, nil, nil
+			}, out)
 		},
 	}
 	flags := cmd.Flags()
@@ -97,6 +99,7 @@ func NewCmdUPHostList(out io.Writer) *cobra.Command {
 	bindOffset(req, flags)
 	bindLimit(req, flags)
 	flags.StringSliceVar(&ids, "uphost-id", nil, "Optional. Resource ID of uphost instances. List those specified uphost instances")
+	watch = bindWatch(flags)
 
 	return cmd
 }
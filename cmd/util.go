@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"github.com/ucloud/ucloud-sdk-go/ucloud/request"
@@ -125,6 +130,17 @@ type concurrentAction struct {
 	wg         *sync.WaitGroup
 	result     chan bool
 	tokens     chan bool
+	retry      int
+	retryIf    func(request.Common) bool
+	results    []actionResult
+	quiet      bool
+}
+
+//actionResult 单个任务的执行结果
+type actionResult struct {
+	Success  bool     `json:"success"`
+	Attempts int      `json:"attempts"`
+	Logs     []string `json:"logs"`
 }
 
 func newConcurrentAction(reqs []request.Common, actionFunc func(request.Common) (bool, []string)) *concurrentAction {
@@ -134,12 +150,57 @@ func newConcurrentAction(reqs []request.Common, actionFunc func(request.Common)
 		wg:         &sync.WaitGroup{},
 		result:     make(chan bool),
 		tokens:     make(chan bool, 10), //控制并发量，最多是个并发
+		results:    make([]actionResult, len(reqs)),
+	}
+}
+
+//setParallel 设置最大并发量
+func (c *concurrentAction) setParallel(parallel int) {
+	if parallel > 0 {
+		c.tokens = make(chan bool, parallel)
 	}
 }
 
-func (c *concurrentAction) actionFuncWrapper(req request.Common) {
+//setRetry 设置失败后的重试次数
+func (c *concurrentAction) setRetry(retry int) {
+	if retry > 0 {
+		c.retry = retry
+	}
+}
+
+//setRetryIf 设置失败后是否可以重试, 如接口调用已成功而等待超时的任务不应重复执行
+func (c *concurrentAction) setRetryIf(retryIf func(request.Common) bool) {
+	c.retryIf = retryIf
+}
+
+//setQuiet 由调用方输出执行结果，不再提示查看日志文件
+func (c *concurrentAction) setQuiet(quiet bool) {
+	c.quiet = quiet
+}
+
+func (c *concurrentAction) actionFuncWrapper(idx int, req request.Common) {
 	c.tokens <- true
-	success, logs := c.actionFunc(req)
+	var success bool
+	var logs []string
+	attempts := 0
+	for attempts <= c.retry {
+		if attempts > 0 {
+			logs = append(logs, fmt.Sprintf("retry %d/%d", attempts, c.retry))
+			time.Sleep(time.Second * time.Duration(attempts))
+		}
+		var _logs []string
+		success, _logs = c.actionFunc(req)
+		logs = append(logs, _logs...)
+		attempts++
+		if success || (c.retryIf != nil && !c.retryIf(req)) {
+			break
+		}
+	}
+	c.results[idx] = actionResult{
+		Success:  success,
+		Attempts: attempts,
+		Logs:     logs,
+	}
 	c.result <- success
 	logs = append([]string{"========================================"}, logs...)
 	base.LogInfo(logs...)
@@ -148,7 +209,8 @@ func (c *concurrentAction) actionFuncWrapper(req request.Common) {
 	c.wg.Done()
 }
 
-func (c *concurrentAction) Do() {
+//Do 执行所有任务，返回与reqs顺序一致的执行结果
+func (c *concurrentAction) Do() []actionResult {
 	count := len(c.reqs)
 	success, fail := 0, 0
 	refresh := ux.NewRefresh()
@@ -171,17 +233,93 @@ func (c *concurrentAction) Do() {
 				if count > 5 {
 					refresh.Do(fmt.Sprintf("total:%d, doing:%d, success:%d, fail:%d", count, len(c.tokens), success, fail))
 				}
-				if count == (success+fail) && fail > 0 {
-					fmt.Printf("Check logs in %s\n", base.GetLogFilePath())
-				}
 			}
 		}
 	}()
 
-	for _, req := range c.reqs {
+	for idx, req := range c.reqs {
 		c.wg.Add(1)
-		go c.actionFuncWrapper(req)
+		go c.actionFuncWrapper(idx, req)
 	}
 
 	c.wg.Wait()
+
+	if !c.quiet {
+		for _, r := range c.results {
+			if !r.Success {
+				fmt.Printf("Check logs in %s\n", base.GetLogFilePath())
+				break
+			}
+		}
+	}
+	return c.results
+}
+
+//stdinIDPatterns 从标准输入读取资源ID时各选项对应的资源ID格式
+var stdinIDPatterns = map[string]*regexp.Regexp{
+	"uhost-id":    regexp.MustCompile(`^uhost-[a-z0-9]+$`),
+	"udisk-id":    regexp.MustCompile(`^bs[a-z]?-[a-z0-9]+$`),
+	"eip-id":      regexp.MustCompile(`^eip-[a-z0-9]+$`),
+	"resource-id": regexp.MustCompile(`^(uhost|uphost|eip|ulb)-[a-z0-9]+$`),
+}
+
+var stdinIDDefaultPattern = regexp.MustCompile(`^[a-z]+-[a-z0-9]+$`)
+
+//bindFromStdin 增加--from-stdin选项，从标准输入读取资源ID填充到flagName对应的选项
+func bindFromStdin(cmd *cobra.Command, flagName string) {
+	var fromStdin bool
+	cmd.Flags().BoolVar(&fromStdin, "from-stdin", false, fmt.Sprintf("Optional. Read %s from stdin, such as the output of list command. Resource IDs are picked from each line", flagName))
+	preRunE := cmd.PreRunE
+	cmd.PreRunE = func(c *cobra.Command, args []string) error {
+		if preRunE != nil {
+			if err := preRunE(c, args); err != nil {
+				return err
+			}
+		}
+		if !fromStdin {
+			return nil
+		}
+		if yes := c.Flags().Lookup("yes"); yes != nil && yes.Value.String() != "true" {
+			return fmt.Errorf("flag --yes is required when reading from stdin")
+		}
+		pattern, ok := stdinIDPatterns[flagName]
+		if !ok {
+			pattern = stdinIDDefaultPattern
+		}
+		ids, err := readIDsFromReader(os.Stdin, pattern)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no %s read from stdin", flagName)
+		}
+		for _, id := range ids {
+			if err := c.Flags().Set(flagName, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+//readIDsFromReader 读取每行中符合pattern的资源ID, 字段以空白或逗号分隔, 忽略空行和#开头的注释
+func readIDsFromReader(r io.Reader, pattern *regexp.Regexp) ([]string, error) {
+	ids := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.Replace(line, ",", " ", -1)) {
+			id := base.PickResourceID(field)
+			if pattern.MatchString(id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
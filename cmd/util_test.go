@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type readIDsFromReaderTest struct {
+	input       string
+	pattern     *regexp.Regexp
+	expectedIDs []string
+}
+
+func (test *readIDsFromReaderTest) run(t *testing.T) {
+	ids, err := readIDsFromReader(strings.NewReader(test.input), test.pattern)
+	if err != nil {
+		t.Fatalf("readIDsFromReader(%q), unexpected error: %v", test.input, err)
+	}
+	if !reflect.DeepEqual(ids, test.expectedIDs) {
+		t.Errorf("readIDsFromReader(%q), expected %v, got %v", test.input, test.expectedIDs, ids)
+	}
+}
+
+func TestReadIDsFromReader(t *testing.T) {
+	tests := []readIDsFromReaderTest{
+		{
+			input:       "uhost-abc12\nuhost-def34\n",
+			pattern:     stdinIDPatterns["uhost-id"],
+			expectedIDs: []string{"uhost-abc12", "uhost-def34"},
+		},
+		{
+			input: "UHostName   ResourceID    Group   PrivateIP\n" +
+				"web         uhost-abc12   prod    10.0.0.1\n" +
+				"\n" +
+				"# ignored uhost-zzz99\n" +
+				"db          uhost-def34   prod    10.0.0.2\n",
+			pattern:     stdinIDPatterns["uhost-id"],
+			expectedIDs: []string{"uhost-abc12", "uhost-def34"},
+		},
+		{
+			input:       "uhost-abc12,uhost-def34 uhost-ghi56/web",
+			pattern:     stdinIDPatterns["uhost-id"],
+			expectedIDs: []string{"uhost-abc12", "uhost-def34", "uhost-ghi56"},
+		},
+		{
+			input:       "bs-abc12 uhost-abc12 bsi-def34 eip-ghi56",
+			pattern:     stdinIDPatterns["udisk-id"],
+			expectedIDs: []string{"bs-abc12", "bsi-def34"},
+		},
+		{
+			input:       "ResourceID\nno id here",
+			pattern:     stdinIDPatterns["eip-id"],
+			expectedIDs: []string{},
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright © 2018 NAME HERE tony.li@ucloud.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ucloud/ucloud-cli/base"
+	"github.com/ucloud/ucloud-cli/ux"
+)
+
+//fetchRowsFunc 获取表格行, cols为nil时显示所有字段
+type fetchRowsFunc func() (rows interface{}, cols []string, err error)
+
+//watchOption --watch --interval --until
+type watchOption struct {
+	enabled  bool
+	interval time.Duration
+	until    string
+}
+
+func bindWatch(flags *pflag.FlagSet) *watchOption {
+	w := &watchOption{}
+	flags.BoolVar(&w.enabled, "watch", false, "Optional. Refresh the list every interval until Ctrl-C is pressed or the until condition is satisfied. Rows whose state changed are highlighted")
+	flags.DurationVar(&w.interval, "interval", 5*time.Second, "Optional. Interval of refreshing, such as 5s, 1m. It takes effect when watch is assigned")
+	flags.StringVar(&w.until, "until", "", "Optional. Stop watching when all rows satisfy the condition, such as State=Running. It takes effect when watch is assigned")
+	flags.SetFlagValues("watch", "true", "false")
+	return w
+}
+
+//printRows 打印一次, 或者在指定--watch时持续刷新
+func printRows(w *watchOption, fetch fetchRowsFunc, out io.Writer) {
+	if w == nil || !w.enabled {
+		rows, cols, err := fetch()
+		if err != nil {
+			base.HandleError(err)
+			return
+		}
+		//不刷新时与原来的list命令输出保持一致
+		if global.JSON || cols == nil {
+			base.PrintList(rows, out)
+		} else {
+			base.PrintTable(rows, cols)
+		}
+		return
+	}
+	err := w.run(fetch, out)
+	if err != nil {
+		base.HandleError(err)
+	}
+}
+
+//printRowsTo 刷新时打印到缓冲区, 高亮状态变化的行
+func printRowsTo(out io.Writer, rows interface{}, cols []string, highlights map[int]bool) {
+	if global.JSON {
+		base.PrintJSON(rows, out)
+	} else {
+		base.FprintTable(out, rows, cols, highlights)
+	}
+}
+
+func (w *watchOption) run(fetch fetchRowsFunc, out io.Writer) error {
+	if w.interval < time.Second {
+		return fmt.Errorf("interval should not be less than 1s, got %s", w.interval)
+	}
+	var untilField, untilValue string
+	if w.until != "" {
+		kv := strings.SplitN(w.until, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("until %q should be in the format of Field=Value, such as State=Running", w.until)
+		}
+		untilField, untilValue = kv[0], kv[1]
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	refresh := ux.NewRefresh()
+	lastStates := make(map[string]string)
+	for {
+		buf := &bytes.Buffer{}
+		fmt.Fprintf(buf, "Every %s, updated at %s. Press Ctrl-C to exit\n", w.interval, time.Now().Format("15:04:05"))
+		rows, cols, err := fetch()
+		satisfied := false
+		if err != nil {
+			fmt.Fprintln(buf, base.ParseError(err))
+		} else {
+			if untilField != "" {
+				if !hasRowField(rows, untilField) {
+					return fmt.Errorf("field %s of until does not exist", untilField)
+				}
+				satisfied = rowsSatisfied(rows, untilField, untilValue)
+			}
+			highlights := make(map[int]bool)
+			states := make(map[string]string)
+			rowsVal := reflect.ValueOf(rows)
+			for i := 0; i < rowsVal.Len(); i++ {
+				key, state := rowKeyState(rowsVal.Index(i))
+				if last, ok := lastStates[key]; ok && last != state {
+					highlights[i] = true
+				}
+				states[key] = state
+			}
+			lastStates = states
+			printRowsTo(buf, rows, cols, highlights)
+		}
+		refresh.Do(strings.TrimRight(buf.String(), "\n"))
+		if satisfied {
+			fmt.Fprintf(out, "all rows satisfy %s\n", w.until)
+			return nil
+		}
+		select {
+		case <-interrupt:
+			return nil
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+//rowKeyState 行的唯一标识(ResourceID或者第一个字段)和状态(State或Status字段, 都没有时为整行内容)
+func rowKeyState(row reflect.Value) (string, string) {
+	row = reflect.Indirect(row)
+	key := fmt.Sprintf("%v", row.Field(0).Interface())
+	if f := row.FieldByName("ResourceID"); f.IsValid() {
+		key = fmt.Sprintf("%v", f.Interface())
+	}
+	for _, name := range []string{"State", "Status"} {
+		if f := row.FieldByName(name); f.IsValid() {
+			return key, fmt.Sprintf("%v", f.Interface())
+		}
+	}
+	return key, fmt.Sprintf("%v", row.Interface())
+}
+
+func hasRowField(rows interface{}, field string) bool {
+	_, ok := reflect.TypeOf(rows).Elem().FieldByName(field)
+	return ok
+}
+
+//rowsSatisfied 所有行的field字段都等于value, 没有行时不满足
+func rowsSatisfied(rows interface{}, field, value string) bool {
+	rowsVal := reflect.ValueOf(rows)
+	if rowsVal.Len() == 0 {
+		return false
+	}
+	for i := 0; i < rowsVal.Len(); i++ {
+		f := reflect.Indirect(rowsVal.Index(i)).FieldByName(field)
+		if fmt.Sprintf("%v", f.Interface()) != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type watchTestRow struct {
+	ResourceID string
+	State      string
+}
+
+type watchRunTest struct {
+	interval    time.Duration
+	until       string
+	rows        []watchTestRow
+	expectedErr string
+	expectedOut string
+}
+
+func (test *watchRunTest) run(t *testing.T) {
+	w := &watchOption{enabled: true, interval: test.interval, until: test.until}
+	fetch := func() (interface{}, []string, error) {
+		return test.rows, nil, nil
+	}
+	buf := new(bytes.Buffer)
+	err := w.run(fetch, buf)
+	if test.expectedErr != "" {
+		if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+			t.Errorf("watch until %q, expected error containing %q, got %v", test.until, test.expectedErr, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("watch until %q, unexpected error: %v", test.until, err)
+	}
+	if buf.String() != test.expectedOut {
+		t.Errorf("watch until %q, expected output %q, got %q", test.until, test.expectedOut, buf.String())
+	}
+}
+
+func TestWatchOptionRun(t *testing.T) {
+	rows := []watchTestRow{{"uhost-1", "Running"}, {"uhost-2", "Running"}}
+	tests := []watchRunTest{
+		{interval: time.Second, until: "State=Running", rows: rows, expectedOut: "all rows satisfy State=Running\n"},
+		{interval: time.Second, until: "State=", rows: []watchTestRow{{"uhost-1", ""}}, expectedOut: "all rows satisfy State=\n"},
+		{interval: time.Second, until: "State", rows: rows, expectedErr: "should be in the format of Field=Value"},
+		{interval: time.Second, until: "=Running", rows: rows, expectedErr: "should be in the format of Field=Value"},
+		{interval: time.Second, until: "Status=Running", rows: rows, expectedErr: "field Status of until does not exist"},
+		{interval: time.Millisecond, until: "State=Running", rows: rows, expectedErr: "interval should not be less than 1s"},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
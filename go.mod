@@ -11,6 +11,7 @@ require (
 	github.com/ucloud/ucloud-sdk-go v0.11.1
 	golang.org/x/sys v0.0.0-20190215142949-d0b11bdaac8a
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 replace (
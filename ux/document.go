@@ -105,6 +105,7 @@ var Doc = newDocument(os.Stdout)
 type Block struct {
 	spinner      *Spin
 	spinnerIndex int
+	spinnerDone  chan struct{}
 	printLineNum int //已打印到屏幕上的行数
 	lines        []string
 	updateLine   chan updateBlockLine
@@ -121,23 +122,25 @@ func (b *Block) Append(text string) {
 	b.updateLine <- updateBlockLine{text, -1}
 }
 
-//SetSpin set spin for block
+//SetSpin set spin for block. If block has a spinner already, wait for it to stop and show the new one in a new line
 func (b *Block) SetSpin(s *Spin) error {
 	if b.spinner != nil {
-		return fmt.Errorf("block has spinner already")
+		<-b.spinnerDone
 	}
 	b.spinner = s
+	b.spinnerDone = make(chan struct{})
 	b.spinnerIndex = len(<-b.getLines)
 	strsCh := b.spinner.renderToString()
-	go func() {
+	go func(done chan struct{}) {
 		for text := range strsCh {
-			if len(<-b.getLines) == 0 {
+			if len(<-b.getLines) <= b.spinnerIndex {
 				b.Append(text)
 			} else {
 				b.Update(text, b.spinnerIndex)
 			}
 		}
-	}()
+		close(done)
+	}(b.spinnerDone)
 	return nil
 }
 
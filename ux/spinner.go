@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ucloud/ucloud-cli/ansi"
@@ -99,15 +100,17 @@ func NewDotSpinner(out io.Writer) *Spinner {
 type Refresh struct {
 	out   io.Writer
 	reset bool
+	lines int
 }
 
-//Do 刷新显示
+//Do 刷新显示, 支持多行文本
 func (r *Refresh) Do(text string) {
 	if r.reset {
-		fmt.Fprintf(r.out, ansi.CursorLeft+ansi.CursorUp(1)+ansi.EraseDown)
+		fmt.Fprintf(r.out, ansi.CursorLeft+ansi.CursorPrevLine(r.lines)+ansi.EraseDown)
 	} else {
 		r.reset = true
 	}
+	r.lines = strings.Count(text, "\n") + 1
 	fmt.Fprintln(r.out, text)
 }
 